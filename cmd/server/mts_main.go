@@ -65,56 +65,13 @@ func main() {
 	// Create API handler
 	handler := api.NewHandler(mtsService, cfg)
 
-	// Setup routes
-	mux := http.NewServeMux()
-	
-	// Health check
-	mux.HandleFunc("/health", handler.HealthCheck)
-	
-	// Legacy endpoint (for backward compatibility)
-	mux.HandleFunc("/api/tickets", handler.PlaceTicket)
-	
-	// New bet type endpoints
-	mux.HandleFunc("/api/bets/single", handler.PlaceSingleBet)
-	mux.HandleFunc("/api/bets/accumulator", handler.PlaceAccumulatorBet)
-	mux.HandleFunc("/api/bets/system", handler.PlaceSystemBet)
-	mux.HandleFunc("/api/bets/banker-system", handler.PlaceBankerSystemBet)
-	mux.HandleFunc("/api/bets/preset", handler.PlacePresetSystemBet)
-	mux.HandleFunc("/api/bets/multi", handler.PlaceMultiBet)
-	
-	// Cashout endpoint
-	mux.HandleFunc("/api/cashout", handler.RequestCashout)
-	
-	// WebSocket endpoint
-	mux.HandleFunc("/ws", wsHandler.ServeWS)
-	
-	// Root endpoint with API documentation
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{
-			"service": "mts-service",
-			"version": "2.0.0",
-			"endpoints": {
-				"health": "/health",
-				"legacy": "/api/tickets",
-				"bets": {
-					"single": "/api/bets/single",
-					"accumulator": "/api/bets/accumulator",
-					"system": "/api/bets/system",
-					"banker_system": "/api/bets/banker-system",
-					"preset": "/api/bets/preset",
-					"multi": "/api/bets/multi"
-				},
-				"cashout": "/api/cashout",
-				"websocket": "/ws?userId=<userId>&token=<token>"
-			}
-		}`))
-	})
+	// Setup routes: /api/v1, the unversioned paths as aliases, and /ws
+	router := api.NewAPIRouter(handler, wsHandler.ServeWS)
 
 	// Start HTTP server
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
-		Handler: enableCORS(mux),
+		Handler: enableCORS(router),
 	}
 
 	go func() {
@@ -139,7 +96,8 @@ func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
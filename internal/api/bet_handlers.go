@@ -2,150 +2,88 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
 	"github.com/gdsZyy/mts-service/internal/models"
+	"github.com/gdsZyy/mts-service/internal/odds"
+	"github.com/gdsZyy/mts-service/internal/service"
+)
+
+// Bet kinds, used as the path suffix of /api/bets/* and /api/quote/*
+const (
+	betKindSingle       = "single"
+	betKindAccumulator  = "accumulator"
+	betKindSystem       = "system"
+	betKindBankerSystem = "banker-system"
+	betKindPreset       = "preset"
+	betKindMulti        = "multi"
 )
 
 // PlaceSingleBet handles single bet requests
 func (h *Handler) PlaceSingleBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
-	}
-
-	var req SingleBetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
-		})
-		return
-	}
-
-	// Validate request
-	if err := validateSingleBetRequest(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
-		})
-		return
-	}
+	h.placeBet(w, r, betKindSingle)
+}
 
-	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
-	selection := convertSelectionRequest(req.Selection)
-	stake := convertStakeRequest(req.Stake)
-	
-	builder.AddSingleBet(selection, stake)
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
-	} else {
-		builder.SetContext(getDefaultContext(h.cfg))
-	}
+// PlaceAccumulatorBet handles accumulator bet requests
+func (h *Handler) PlaceAccumulatorBet(w http.ResponseWriter, r *http.Request) {
+	h.placeBet(w, r, betKindAccumulator)
+}
 
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
-	response, err := h.mtsService.SendTicket(ticket)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 500, Message: "Failed to send ticket", Details: err.Error()},
-		})
-		return
-	}
+// PlaceSystemBet handles system bet requests
+func (h *Handler) PlaceSystemBet(w http.ResponseWriter, r *http.Request) {
+	h.placeBet(w, r, betKindSystem)
+}
 
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
-	})
+// PlaceBankerSystemBet handles banker system bet requests
+func (h *Handler) PlaceBankerSystemBet(w http.ResponseWriter, r *http.Request) {
+	h.placeBet(w, r, betKindBankerSystem)
 }
 
-// PlaceAccumulatorBet handles accumulator bet requests
-func (h *Handler) PlaceAccumulatorBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
-	}
+// PlacePresetSystemBet handles preset system bet requests (Trixie, Yankee, etc.)
+func (h *Handler) PlacePresetSystemBet(w http.ResponseWriter, r *http.Request) {
+	h.placeBet(w, r, betKindPreset)
+}
 
-	var req AccumulatorBetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
-		})
-		return
-	}
+// PlaceMultiBet handles multi-bet requests (multiple bets in one ticket)
+func (h *Handler) PlaceMultiBet(w http.ResponseWriter, r *http.Request) {
+	h.placeBet(w, r, betKindMulti)
+}
 
-	// Validate request
-	if err := validateAccumulatorBetRequest(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
-		})
+// placeBet builds the ticket for a bet kind and sends it to MTS
+func (h *Handler) placeBet(w http.ResponseWriter, r *http.Request, kind string) {
+	format, apiErr := responseOddsFormat(r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
 		return
 	}
 
-	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
-	
-	selections := make([]models.Selection, len(req.Selections))
-	for i, sel := range req.Selections {
-		selections[i] = convertSelectionRequest(sel)
-	}
-	stake := convertStakeRequest(req.Stake)
-	
-	builder.AddAccumulatorBet(selections, stake)
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
-	} else {
-		builder.SetContext(getDefaultContext(h.cfg))
-	}
-	
-
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
-	response, err := h.mtsService.SendTicket(ticket)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 500, Message: "Failed to send ticket", Details: err.Error()},
-		})
+	ticket, apiErr := h.buildTicket(kind, r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
-	})
+	h.sendTicket(w, ticket, format)
 }
 
-// PlaceSystemBet handles system bet requests
-func (h *Handler) PlaceSystemBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
+// AcceptReoffer handles /api/reoffer/accept: it places the ticket again with
+// the alternative stakes MTS offered, under the ID "<ticketId>-reoffer"
+func (h *Handler) AcceptReoffer(w http.ResponseWriter, r *http.Request) {
+	format, apiErr := responseOddsFormat(r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
 		return
 	}
 
-	var req SystemBetRequest
+	var req AcceptReofferRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
@@ -153,38 +91,37 @@ func (h *Handler) PlaceSystemBet(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-
-	// Validate request
-	if err := validateSystemBetRequest(&req); err != nil {
+	if req.TicketID == "" {
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
+			Error:   &APIError{Code: 400, Message: "Validation failed", Details: "ticketId is required"},
 		})
 		return
 	}
 
-	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
-	
-	selections := make([]models.Selection, len(req.Selections))
-	for i, sel := range req.Selections {
-		selections[i] = convertSelectionRequest(sel)
-	}
-	stake := convertStakeRequest(req.Stake)
-	
-	builder.AddSystemBet(req.Size, selections, stake)
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
-	} else {
-		builder.SetContext(getDefaultContext(h.cfg))
+	_, ticket, err := h.mtsService.AcceptReoffer(req.TicketID, generateCorrelationID(), h.checkTicket)
+	if err != nil {
+		apiErr := &APIError{Code: http.StatusBadRequest, Message: "Cannot accept alternative stake", Details: err.Error()}
+		switch {
+		case errors.Is(err, service.ErrReofferNotFound):
+			apiErr.Code = http.StatusNotFound
+		case errors.Is(err, service.ErrReofferExpired):
+			apiErr.Code = http.StatusGone
+		default:
+			if errs, ok := err.(models.BuilderErrors); ok {
+				apiErr.Errors = errs
+			}
+		}
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
+		return
 	}
-	
 
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
+	h.sendTicket(w, ticket, format)
+}
+
+// sendTicket sends a built ticket to MTS and writes the response with its
+// explained issues and any alternative stake offer
+func (h *Handler) sendTicket(w http.ResponseWriter, ticket *models.TicketRequest, format odds.Format) {
 	response, err := h.mtsService.SendTicket(ticket)
 	if err != nil {
 		respondJSON(w, http.StatusInternalServerError, APIResponse{
@@ -194,289 +131,283 @@ func (h *Handler) PlaceSystemBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	record := h.cfg.Currencies.Record(ticket, response)
+	if record != nil && len(record.ExchangeRates) > 0 {
+		log.Printf("Ticket %s exchange rates: %+v", ticket.Content.TicketID, record.ExchangeRates)
+	}
+
 	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
+		Success:  true,
+		Data:     response.WithOddsFormat(format),
+		Issues:   models.InterpretResponse(ticket, response),
+		Reoffer:  h.mtsService.RecordReoffer(ticket, response),
+		Currency: record,
+		Bets:     models.MapBetDetails(ticket, response),
+		Splits:   models.StakeSplits(ticket),
 	})
 }
 
-// PlaceBankerSystemBet handles banker system bet requests
-func (h *Handler) PlaceBankerSystemBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
+// betRequest is implemented by every /api/bets/* request body
+type betRequest interface {
+	validate() error
+	addBets(builder *models.TicketBuilder, currencies *currency.Registry) *APIError
+	ticketID() string
+	context() *ContextRequest
+}
+
+// newBetRequest returns an empty request body for a bet kind, or nil if unknown
+func newBetRequest(kind string) betRequest {
+	switch kind {
+	case betKindSingle:
+		return &SingleBetRequest{}
+	case betKindAccumulator:
+		return &AccumulatorBetRequest{}
+	case betKindSystem:
+		return &SystemBetRequest{}
+	case betKindBankerSystem:
+		return &BankerSystemBetRequest{}
+	case betKindPreset:
+		return &PresetSystemBetRequest{}
+	case betKindMulti:
+		return &MultiBetRequest{}
+	}
+	return nil
+}
+
+// buildTicket decodes and validates the request body for a bet kind and builds
+// the ticket without sending it. The error code doubles as the HTTP status.
+func (h *Handler) buildTicket(kind string, r *http.Request) (*models.TicketRequest, *APIError) {
+	req := newBetRequest(kind)
+	if req == nil {
+		return nil, &APIError{Code: 404, Message: "Unknown bet type", Details: fmt.Sprintf("Unknown type: %s", kind)}
 	}
 
-	var req BankerSystemBetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
-		})
-		return
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()}
 	}
 
 	// Validate request
-	if err := validateBankerSystemBetRequest(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
-		})
-		return
+	if err := req.validate(); err != nil {
+		return nil, &APIError{Code: 400, Message: "Validation failed", Details: err.Error()}
 	}
 
 	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
-	
-	bankers := make([]models.Selection, len(req.Bankers))
-	for i, sel := range req.Bankers {
-		bankers[i] = convertSelectionRequest(sel)
-	}
-	
-	selections := make([]models.Selection, len(req.Selections))
-	for i, sel := range req.Selections {
-		selections[i] = convertSelectionRequest(sel)
+	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.ticketID())
+	if apiErr := req.addBets(builder, h.cfg.Currencies); apiErr != nil {
+		return nil, apiErr
 	}
-	stake := convertStakeRequest(req.Stake)
-	
-	builder.AddBankerSystemBet(bankers, req.Size, selections, stake)
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
+
+	if req.context() != nil {
+		builder.SetContext(convertContextRequest(req.context(), h.cfg))
 	} else {
 		builder.SetContext(getDefaultContext(h.cfg))
 	}
-	
 
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
-	response, err := h.mtsService.SendTicket(ticket)
+	ticket, err := builder.Build(generateCorrelationID())
+	if err == nil {
+		err = h.checkTicket(ticket)
+	}
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 500, Message: "Failed to send ticket", Details: err.Error()},
-		})
-		return
+		apiErr := &APIError{Code: 400, Message: "Validation failed", Details: err.Error()}
+		if errs, ok := err.(models.BuilderErrors); ok {
+			apiErr.Errors = errs
+		}
+		return nil, apiErr
 	}
-
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
-	})
+	return ticket, nil
 }
 
-// PlacePresetSystemBet handles preset system bet requests (Trixie, Yankee, etc.)
-func (h *Handler) PlacePresetSystemBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
+// checkTicket checks the stakes of a built ticket against the currency
+// registry and the local limits
+func (h *Handler) checkTicket(ticket *models.TicketRequest) error {
+	if err := h.cfg.Currencies.CheckTicket(ticket); err != nil {
+		return err
 	}
+	return h.cfg.Limits.Check(ticket, h.cfg.Currencies)
+}
 
-	var req PresetSystemBetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
-		})
-		return
-	}
+func (req *SingleBetRequest) validate() error          { return validateSingleBetRequest(req) }
+func (req *SingleBetRequest) ticketID() string         { return req.TicketID }
+func (req *SingleBetRequest) context() *ContextRequest { return req.Context }
 
-	// Validate request
-	if err := validatePresetSystemBetRequest(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
-		})
-		return
-	}
+func (req *AccumulatorBetRequest) validate() error          { return validateAccumulatorBetRequest(req) }
+func (req *AccumulatorBetRequest) ticketID() string         { return req.TicketID }
+func (req *AccumulatorBetRequest) context() *ContextRequest { return req.Context }
 
-	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
-	
-	selections := make([]models.Selection, len(req.Selections))
-	for i, sel := range req.Selections {
+func (req *SystemBetRequest) validate() error          { return validateSystemBetRequest(req) }
+func (req *SystemBetRequest) ticketID() string         { return req.TicketID }
+func (req *SystemBetRequest) context() *ContextRequest { return req.Context }
+
+func (req *BankerSystemBetRequest) validate() error          { return validateBankerSystemBetRequest(req) }
+func (req *BankerSystemBetRequest) ticketID() string         { return req.TicketID }
+func (req *BankerSystemBetRequest) context() *ContextRequest { return req.Context }
+
+func (req *PresetSystemBetRequest) validate() error          { return validatePresetSystemBetRequest(req) }
+func (req *PresetSystemBetRequest) ticketID() string         { return req.TicketID }
+func (req *PresetSystemBetRequest) context() *ContextRequest { return req.Context }
+
+func (req *MultiBetRequest) validate() error          { return validateMultiBetRequest(req) }
+func (req *MultiBetRequest) ticketID() string         { return req.TicketID }
+func (req *MultiBetRequest) context() *ContextRequest { return req.Context }
+
+func convertSelectionRequests(reqs []SelectionRequest) []models.Selection {
+	selections := make([]models.Selection, len(reqs))
+	for i, sel := range reqs {
 		selections[i] = convertSelectionRequest(sel)
 	}
-	stake := convertStakeRequest(req.Stake)
-	
-	// Call appropriate method based on type
-	switch strings.ToLower(req.Type) {
-	case "trixie":
-		builder.AddTrixieBet(selections, stake)
-	case "patent":
-		builder.AddPatentBet(selections, stake)
-	case "yankee":
-		builder.AddYankeeBet(selections, stake)
-	case "lucky15", "lucky_15":
-		builder.AddLucky15Bet(selections, stake)
-	case "super_yankee", "canadian":
-		builder.AddSuperYankeeBet(selections, stake)
-	case "lucky31", "lucky_31":
-		builder.AddLucky31Bet(selections, stake)
-	case "heinz":
-		builder.AddHeinzBet(selections, stake)
-	case "lucky63", "lucky_63":
-		builder.AddLucky63Bet(selections, stake)
-	case "super_heinz":
-		builder.AddSuperHeinzBet(selections, stake)
-	case "goliath":
-		builder.AddGoliathBet(selections, stake)
-	default:
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid preset type", Details: fmt.Sprintf("Unknown type: %s", req.Type)},
-		})
-		return
-	}
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
-	} else {
-		builder.SetContext(getDefaultContext(h.cfg))
-	}
-	
+	return selections
+}
 
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
-	response, err := h.mtsService.SendTicket(ticket)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 500, Message: "Failed to send ticket", Details: err.Error()},
-		})
-		return
-	}
+func (req *SingleBetRequest) addBets(builder *models.TicketBuilder, _ *currency.Registry) *APIError {
+	builder.AddSingleBet(convertSelectionRequest(req.Selection), convertStakeRequests(req.Stake)...)
+	addEachWay(builder, req.EachWay)
+	return nil
+}
 
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
-	})
+func (req *AccumulatorBetRequest) addBets(builder *models.TicketBuilder, _ *currency.Registry) *APIError {
+	builder.AddAccumulatorBet(convertSelectionRequests(req.Selections), convertStakeRequests(req.Stake)...)
+	addEachWay(builder, req.EachWay)
+	return nil
 }
 
-// PlaceMultiBet handles multi-bet requests (multiple bets in one ticket)
-func (h *Handler) PlaceMultiBet(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
+func (req *SystemBetRequest) addBets(builder *models.TicketBuilder, currencies *currency.Registry) *APIError {
+	size := req.Size
+	if len(size) == 0 {
+		size = sizeStakeSizes(req.SizeStakes)
 	}
+	builder.AddSystemBet(size, convertSelectionRequests(req.Selections), systemStakes(req.Stake, req.SizeStakes)...)
+	applySystemStakes(builder, req.Stake, req.SizeStakes, currencies)
+	addEachWay(builder, req.EachWay)
+	return nil
+}
 
-	var req MultiBetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
-		})
-		return
+func (req *BankerSystemBetRequest) addBets(builder *models.TicketBuilder, currencies *currency.Registry) *APIError {
+	bankers := convertSelectionRequests(req.Bankers)
+	size := req.Size
+	if len(size) == 0 {
+		size = sizeStakeSizes(req.SizeStakes)
 	}
+	builder.AddBankerSystemBet(bankers, size, convertSelectionRequests(req.Selections), systemStakes(req.Stake, req.SizeStakes)...)
+	applySystemStakes(builder, req.Stake, req.SizeStakes, currencies)
+	addEachWay(builder, req.EachWay)
+	return nil
+}
 
-	// Validate request
-	if err := validateMultiBetRequest(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
-		})
-		return
+func (req *PresetSystemBetRequest) addBets(builder *models.TicketBuilder, currencies *currency.Registry) *APIError {
+	selections := convertSelectionRequests(req.Selections)
+	if _, ok := models.LookupPreset(req.Type); !ok {
+		return &APIError{Code: 400, Message: "Invalid preset type", Details: fmt.Sprintf("Unknown type: %s", req.Type)}
 	}
+	builder.AddPresetBet(req.Type, selections, systemStakes(req.Stake, req.SizeStakes)...)
+	applySystemStakes(builder, req.Stake, req.SizeStakes, currencies)
+	addEachWay(builder, req.EachWay)
+	return nil
+}
 
-	// Build ticket using TicketBuilder
-	builder := models.NewTicketBuilder(h.cfg.OperatorID, req.TicketID)
-	
+func (req *MultiBetRequest) addBets(builder *models.TicketBuilder, currencies *currency.Registry) *APIError {
 	for _, bet := range req.Bets {
-		selections := make([]models.Selection, len(bet.Selections))
-		for i, sel := range bet.Selections {
-			selections[i] = convertSelectionRequest(sel)
+		selections := convertSelectionRequests(bet.Selections)
+		stakes := convertStakeRequests(bet.Stake)
+		size := bet.Size
+		if bet.combinatorial() {
+			stakes = systemStakes(bet.Stake, bet.SizeStakes)
+			if len(size) == 0 {
+				size = sizeStakeSizes(bet.SizeStakes)
+			}
 		}
-		stake := convertStakeRequest(bet.Stake)
-		
-		switch strings.ToLower(bet.Type) {
-		case "single":
+
+		switch kind := strings.ToLower(bet.Type); {
+		case bet.Notation != "":
+			builder.AddNotationBet(bet.Notation, selections, stakes...)
+		case kind == "single":
 			if len(selections) != 1 {
-				respondJSON(w, http.StatusBadRequest, APIResponse{
-					Success: false,
-					Error:   &APIError{Code: 400, Message: "Single bet must have exactly 1 selection"},
-				})
-				return
-			}
-			builder.AddSingleBet(selections[0], stake)
-		case "accumulator":
-			builder.AddAccumulatorBet(selections, stake)
-		case "system":
-			builder.AddSystemBet(bet.Size, selections, stake)
-		case "banker_system":
-			bankers := make([]models.Selection, len(bet.Bankers))
-			for i, sel := range bet.Bankers {
-				bankers[i] = convertSelectionRequest(sel)
+				return &APIError{Code: 400, Message: "Single bet must have exactly 1 selection"}
 			}
-			builder.AddBankerSystemBet(bankers, bet.Size, selections, stake)
+			builder.AddSingleBet(selections[0], stakes...)
+		case kind == "accumulator":
+			builder.AddAccumulatorBet(selections, stakes...)
+		case kind == "system":
+			builder.AddSystemBet(size, selections, stakes...)
+		case kind == "banker_system":
+			builder.AddBankerSystemBet(convertSelectionRequests(bet.Bankers), size, selections, stakes...)
 		default:
 			// Try preset types
-			switch strings.ToLower(bet.Type) {
-			case "trixie":
-				builder.AddTrixieBet(selections, stake)
-			case "patent":
-				builder.AddPatentBet(selections, stake)
-			case "yankee":
-				builder.AddYankeeBet(selections, stake)
-			case "lucky15", "lucky_15":
-				builder.AddLucky15Bet(selections, stake)
-			case "super_yankee", "canadian":
-				builder.AddSuperYankeeBet(selections, stake)
-			case "lucky31", "lucky_31":
-				builder.AddLucky31Bet(selections, stake)
-			case "heinz":
-				builder.AddHeinzBet(selections, stake)
-			case "lucky63", "lucky_63":
-				builder.AddLucky63Bet(selections, stake)
-			case "super_heinz":
-				builder.AddSuperHeinzBet(selections, stake)
-			case "goliath":
-				builder.AddGoliathBet(selections, stake)
-			default:
-				respondJSON(w, http.StatusBadRequest, APIResponse{
-					Success: false,
-					Error:   &APIError{Code: 400, Message: "Invalid bet type", Details: fmt.Sprintf("Unknown type: %s", bet.Type)},
-				})
-				return
+			if _, ok := models.LookupPreset(bet.Type); !ok {
+				return &APIError{Code: 400, Message: "Invalid bet type", Details: fmt.Sprintf("Unknown type: %s", bet.Type)}
 			}
+			builder.AddPresetBet(bet.Type, selections, stakes...)
+		}
+		if bet.combinatorial() {
+			applySystemStakes(builder, bet.Stake, bet.SizeStakes, currencies)
 		}
+		if bet.BetID != "" {
+			builder.SetBetID(bet.BetID)
+		}
+		addEachWay(builder, bet.EachWay)
 	}
-	
-	if req.Context != nil {
-		builder.SetContext(convertContextRequest(req.Context, h.cfg))
-	} else {
-		builder.SetContext(getDefaultContext(h.cfg))
+	return nil
+}
+
+// combinatorial reports whether the bet is a system, banker or preset bet,
+// whose stake may be a total to split or given per size
+func (b BetDefinition) combinatorial() bool {
+	if b.Notation != "" {
+		return true
 	}
-	
+	kind := strings.ToLower(b.Type)
+	return kind != "single" && kind != "accumulator"
+}
 
-	ticket := builder.Build(generateCorrelationID())
-	
-	// Send to MTS
-	response, err := h.mtsService.SendTicket(ticket)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 500, Message: "Failed to send ticket", Details: err.Error()},
-		})
+// systemStakes returns the stakes for adding a system, banker or preset bet.
+// With sizeStakes these are placeholders that applySystemStakes replaces.
+func systemStakes(stake StakeRequests, sizeStakes []SizeStakeRequest) []models.Stake {
+	if len(sizeStakes) > 0 {
+		return convertStakeRequests(sizeStakes[0].Stake)
+	}
+	return convertStakeRequests(stake)
+}
+
+// applySystemStakes gives the bet just added its per-size stakes, or splits
+// a total stake into unit stakes in the smallest unit of its currency
+func applySystemStakes(builder *models.TicketBuilder, stake StakeRequests, sizeStakes []SizeStakeRequest, currencies *currency.Registry) {
+	if len(sizeStakes) > 0 {
+		stakes := make([]models.SizeStake, len(sizeStakes))
+		for i, s := range sizeStakes {
+			stakes[i] = models.SizeStake{Size: s.Size, Stakes: convertStakeRequests(s.Stake)}
+		}
+		builder.SetSizeStakes(stakes)
+		return
+	}
+	if stake.mode() != "total" {
 		return
 	}
+	decimals := decimal.MTSScale
+	if c, err := currencies.Lookup(stake[0].Currency); err == nil {
+		decimals = c.Decimals
+	}
+	builder.SplitTotalStake(decimals)
+}
 
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    response,
-	})
+// sizeStakeSizes returns the sizes given stakes, ascending
+func sizeStakeSizes(sizeStakes []SizeStakeRequest) []int {
+	sizes := make([]int, len(sizeStakes))
+	for i, s := range sizeStakes {
+		sizes[i] = s.Size
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// addEachWay adds the place part of the bet just added when each-way terms are given
+func addEachWay(builder *models.TicketBuilder, ew *EachWayRequest) {
+	if ew != nil {
+		builder.AddEachWay(convertEachWayRequest(ew))
+	}
+}
+
+// ListPresets returns the preset registry, the types accepted by /api/bets/preset
+func (h *Handler) ListPresets(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: models.Presets()})
 }
 
 // Helper function to respond with JSON
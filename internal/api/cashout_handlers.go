@@ -4,22 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
 	"github.com/gdsZyy/mts-service/internal/models"
 )
 
 // RequestCashout handles cashout-inform requests
 func (h *Handler) RequestCashout(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondJSON(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   &APIError{Code: 405, Message: "Method not allowed"},
-		})
-		return
-	}
-
 	var req CashoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondJSON(w, http.StatusBadRequest, APIResponse{
@@ -30,7 +23,7 @@ func (h *Handler) RequestCashout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate request
-	if err := validateCashoutRequest(&req); err != nil {
+	if err := validateCashoutRequest(&req, h.cfg.Currencies); err != nil {
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
 			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
@@ -57,7 +50,61 @@ func (h *Handler) RequestCashout(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func validateCashoutRequest(req *CashoutRequest) error {
+// ValueCashout handles /api/cashout/value: it values a placed ticket from the
+// current probabilities and results of a cashout-build reply and compares the
+// suggested amount with the MTS offer. Nothing is sent to MTS.
+func (h *Handler) ValueCashout(w http.ResponseWriter, r *http.Request) {
+	var req CashoutValueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   &APIError{Code: 400, Message: "Invalid request body", Details: err.Error()},
+		})
+		return
+	}
+
+	rules, err := h.cfg.CashoutRules()
+	if err == nil && req.Margin != "" {
+		rules.Margin, err = decimal.Parse(req.Margin)
+	}
+	if err == nil && len(req.Bets) == 0 {
+		err = fmt.Errorf("at least one bet is required")
+	}
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   &APIError{Code: 400, Message: "Validation failed", Details: err.Error()},
+		})
+		return
+	}
+
+	var details []models.CashoutSelectionDetail
+	for _, bet := range req.BetDetails {
+		details = append(details, bet.SelectionDetails...)
+	}
+	ticket := &models.TicketRequest{Content: models.TicketContent{TicketID: req.TicketID, Bets: req.Bets}}
+
+	valuation, err := models.ValueCashout(ticket, details, rules)
+	if err == nil {
+		err = valuation.CompareWith(req.Cashout)
+	}
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   &APIError{Code: 400, Message: "Valuation failed", Details: err.Error()},
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    valuation,
+	})
+}
+
+// validateCashoutRequest checks a cashout request and normalises its payout
+// currencies and amounts with the currency registry
+func validateCashoutRequest(req *CashoutRequest, currencies *currency.Registry) error {
 	if req.CashoutID == "" {
 		return fmt.Errorf("cashoutId is required")
 	}
@@ -91,8 +138,8 @@ func validateCashoutRequest(req *CashoutRequest) error {
 		if req.Percentage == "" {
 			return fmt.Errorf("percentage is required for partial cashout")
 		}
-		percentage, err := strconv.ParseFloat(req.Percentage, 64)
-		if err != nil || percentage <= 0 || percentage > 1 {
+		percentage, err := decimal.Parse(req.Percentage)
+		if err != nil || percentage.Sign() <= 0 || percentage.GreaterThan(decimal.One) {
 			return fmt.Errorf("percentage must be a valid number between 0 and 1")
 		}
 	}
@@ -114,10 +161,11 @@ func validateCashoutRequest(req *CashoutRequest) error {
 		if payout.Amount == "" {
 			return fmt.Errorf("payout[%d].amount is required", i)
 		}
-		amount, err := strconv.ParseFloat(payout.Amount, 64)
-		if err != nil || amount <= 0 {
-			return fmt.Errorf("payout[%d].amount must be a valid number greater than 0", i)
+		code, amount, err := currencies.NormalizeAmount(payout.Currency, payout.Amount)
+		if err != nil {
+			return fmt.Errorf("payout[%d]: %w", i, err)
 		}
+		req.Payout[i].Currency, req.Payout[i].Amount = code, amount
 	}
 	
 	return nil
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gdsZyy/mts-service/internal/config"
+	"github.com/gdsZyy/mts-service/internal/decimal"
 	"github.com/gdsZyy/mts-service/internal/models"
 	"github.com/gdsZyy/mts-service/internal/service"
 	"github.com/google/uuid"
@@ -38,6 +39,7 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"status":    status,
 		"timestamp": time.Now().Unix(),
 		"service":   "mts-service",
+		"queued":    h.mtsService.QueueLength(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -46,11 +48,6 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 
 // PlaceTicket handles ticket placement requests
 func (h *Handler) PlaceTicket(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req PlaceTicketRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body", err)
@@ -65,6 +62,14 @@ func (h *Handler) PlaceTicket(w http.ResponseWriter, r *http.Request) {
 
 	// Build MTS ticket request
 	ticket := h.buildTicketRequest(&req)
+	if err := h.cfg.Currencies.CheckTicket(ticket); err != nil {
+		respondError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+	if err := h.cfg.Limits.Check(ticket, h.cfg.Currencies); err != nil {
+		respondError(w, http.StatusBadRequest, "Limit exceeded", err)
+		return
+	}
 
 	log.Printf("Sending ticket: %s (correlation: %s)", ticket.Content.TicketID, ticket.CorrelationID)
 
@@ -128,6 +133,9 @@ func validatePlaceTicketRequest(req *PlaceTicketRequest) error {
 	if req.TotalStake == "" {
 		return fmt.Errorf("totalStake is required")
 	}
+	if _, err := decimal.Parse(req.TotalStake); err != nil {
+		return fmt.Errorf("totalStake must be a valid number")
+	}
 	if len(req.Bets) == 0 {
 		return fmt.Errorf("at least one bet is required")
 	}
@@ -158,15 +166,15 @@ func validatePlaceTicketRequest(req *PlaceTicketRequest) error {
 
 // formatAmountTo8Decimals formats an amount string to have exactly 8 decimal places
 func formatAmountTo8Decimals(amount string) string {
-	// Parse the amount as a float
-	val, err := strconv.ParseFloat(amount, 64)
+	// Parse exactly so large or high-precision amounts keep every digit
+	val, err := decimal.Parse(amount)
 	if err != nil {
 		log.Printf("Warning: Failed to parse amount '%s': %v, using as-is", amount, err)
 		return amount
 	}
 
 	// Format to 8 decimal places
-	return fmt.Sprintf("%.8f", val)
+	return val.StringFixed(decimal.MTSScale)
 }
 
 func (h *Handler) buildTicketRequest(req *PlaceTicketRequest) *models.TicketRequest {
@@ -303,8 +311,9 @@ func (h *Handler) buildTicketRequest(req *PlaceTicketRequest) *models.TicketRequ
 			stakeAmount := betInput.Amount
 			if stakeAmount == "" {
 				// Calculate stake from total stake if not provided
-				totalStakeVal, _ := strconv.ParseFloat(req.TotalStake, 64)
-				stakeAmount = fmt.Sprintf("%.8f", totalStakeVal/float64(len(req.Bets)))
+				// Round down so the split never exceeds the total stake
+				totalStakeVal, _ := decimal.Parse(req.TotalStake)
+				stakeAmount = totalStakeVal.DivInt(int64(len(req.Bets)), decimal.MTSScale, decimal.RoundDown).String()
 			} else {
 				stakeAmount = formatAmountTo8Decimals(stakeAmount)
 			}
@@ -313,6 +322,7 @@ func (h *Handler) buildTicketRequest(req *PlaceTicketRequest) *models.TicketRequ
 			stakeMode := "total"
 
 			bets[i] = models.Bet{
+				Index:      i,
 				Selections: selections,
 				Stake: []models.Stake{
 					{
@@ -370,15 +380,14 @@ func (h *Handler) buildTicketRequest(req *PlaceTicketRequest) *models.TicketRequ
 		}
 	}
 
+	// respondError writes an error in the APIResponse envelope; the status
+	// doubles as the error code
 	func respondError(w http.ResponseWriter, status int, message string, err error) {
 		log.Printf("Error: %s - %v", message, err)
 
-		response := map[string]interface{}{
-			"error":   message,
-			"details": err.Error(),
+		apiErr := &APIError{Code: status, Message: message}
+		if err != nil {
+			apiErr.Details = err.Error()
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(status)
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, status, APIResponse{Success: false, Error: apiErr})
 	}
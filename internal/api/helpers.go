@@ -2,11 +2,15 @@ package api
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gdsZyy/mts-service/internal/config"
+	"github.com/gdsZyy/mts-service/internal/decimal"
 	"github.com/gdsZyy/mts-service/internal/models"
+	"github.com/gdsZyy/mts-service/internal/odds"
+	"github.com/gdsZyy/mts-service/internal/uof"
 )
 
 // Validation functions
@@ -18,9 +22,12 @@ func validateSingleBetRequest(req *SingleBetRequest) error {
 	if err := validateSelectionRequest(&req.Selection); err != nil {
 		return fmt.Errorf("selection: %w", err)
 	}
-	if err := validateStakeRequest(&req.Stake); err != nil {
+	if err := validateStakeRequests(req.Stake); err != nil {
 		return fmt.Errorf("stake: %w", err)
 	}
+	if err := validateEachWayRequest(req.EachWay, req.Selection); err != nil {
+		return fmt.Errorf("eachWay: %w", err)
+	}
 	return nil
 }
 
@@ -36,9 +43,12 @@ func validateAccumulatorBetRequest(req *AccumulatorBetRequest) error {
 			return fmt.Errorf("selection[%d]: %w", i, err)
 		}
 	}
-	if err := validateStakeRequest(&req.Stake); err != nil {
+	if err := validateStakeRequests(req.Stake); err != nil {
 		return fmt.Errorf("stake: %w", err)
 	}
+	if err := validateEachWayRequest(req.EachWay, req.Selections...); err != nil {
+		return fmt.Errorf("eachWay: %w", err)
+	}
 	return nil
 }
 
@@ -46,7 +56,7 @@ func validateSystemBetRequest(req *SystemBetRequest) error {
 	if req.TicketID == "" {
 		return fmt.Errorf("ticketId is required")
 	}
-	if len(req.Size) == 0 {
+	if len(req.Size) == 0 && len(req.SizeStakes) == 0 {
 		return fmt.Errorf("size is required")
 	}
 	if len(req.Selections) < 2 {
@@ -62,12 +72,11 @@ func validateSystemBetRequest(req *SystemBetRequest) error {
 			return fmt.Errorf("selection[%d]: %w", i, err)
 		}
 	}
-	if err := validateStakeRequest(&req.Stake); err != nil {
-		return fmt.Errorf("stake: %w", err)
+	if err := validateSystemStakes(req.Stake, req.SizeStakes); err != nil {
+		return err
 	}
-	// System bets should use "unit" mode
-	if req.Stake.Mode != "unit" {
-		return fmt.Errorf("system bet stake mode must be 'unit'")
+	if err := validateEachWayRequest(req.EachWay, req.Selections...); err != nil {
+		return fmt.Errorf("eachWay: %w", err)
 	}
 	return nil
 }
@@ -79,10 +88,10 @@ func validateBankerSystemBetRequest(req *BankerSystemBetRequest) error {
 	if len(req.Bankers) < 1 {
 		return fmt.Errorf("banker system bet requires at least 1 banker")
 	}
-	if len(req.Selections) < 1 {
-		return fmt.Errorf("banker system bet requires at least 1 non-banker selection")
+	if len(req.Selections) < 2 {
+		return fmt.Errorf("selections: banker system bet requires at least 2 non-banker selections")
 	}
-	if len(req.Size) == 0 {
+	if len(req.Size) == 0 && len(req.SizeStakes) == 0 {
 		return fmt.Errorf("size is required")
 	}
 	for _, s := range req.Size {
@@ -100,11 +109,11 @@ func validateBankerSystemBetRequest(req *BankerSystemBetRequest) error {
 			return fmt.Errorf("selection[%d]: %w", i, err)
 		}
 	}
-	if err := validateStakeRequest(&req.Stake); err != nil {
-		return fmt.Errorf("stake: %w", err)
+	if err := validateSystemStakes(req.Stake, req.SizeStakes); err != nil {
+		return err
 	}
-	if req.Stake.Mode != "unit" {
-		return fmt.Errorf("banker system bet stake mode must be 'unit'")
+	if err := validateEachWayRequest(req.EachWay, append(append([]SelectionRequest{}, req.Bankers...), req.Selections...)...); err != nil {
+		return fmt.Errorf("eachWay: %w", err)
 	}
 	return nil
 }
@@ -117,31 +126,12 @@ func validatePresetSystemBetRequest(req *PresetSystemBetRequest) error {
 		return fmt.Errorf("type is required")
 	}
 	
-	// Validate selection count based on type
-	requiredCount := map[string]int{
-		"trixie":       3,
-		"patent":       3,
-		"yankee":       4,
-		"lucky15":      4,
-		"lucky_15":     4,
-		"super_yankee": 5,
-		"canadian":     5,
-		"lucky31":      5,
-		"lucky_31":     5,
-		"heinz":        6,
-		"lucky63":      6,
-		"lucky_63":     6,
-		"super_heinz":  7,
-		"goliath":      8,
-	}
-	
-	required, ok := requiredCount[req.Type]
+	preset, ok := models.LookupPreset(req.Type)
 	if !ok {
 		return fmt.Errorf("unknown preset type: %s", req.Type)
 	}
-	
-	if len(req.Selections) != required {
-		return fmt.Errorf("%s requires exactly %d selections, got %d", req.Type, required, len(req.Selections))
+	if _, err := preset.Layout(len(req.Selections)); err != nil {
+		return err
 	}
 	
 	for i, sel := range req.Selections {
@@ -149,11 +139,11 @@ func validatePresetSystemBetRequest(req *PresetSystemBetRequest) error {
 			return fmt.Errorf("selection[%d]: %w", i, err)
 		}
 	}
-	if err := validateStakeRequest(&req.Stake); err != nil {
-		return fmt.Errorf("stake: %w", err)
+	if err := validateSystemStakes(req.Stake, req.SizeStakes); err != nil {
+		return err
 	}
-	if req.Stake.Mode != "unit" {
-		return fmt.Errorf("preset system bet stake mode must be 'unit'")
+	if err := validateEachWayRequest(req.EachWay, req.Selections...); err != nil {
+		return fmt.Errorf("eachWay: %w", err)
 	}
 	return nil
 }
@@ -166,8 +156,15 @@ func validateMultiBetRequest(req *MultiBetRequest) error {
 		return fmt.Errorf("at least one bet is required")
 	}
 	for i, bet := range req.Bets {
-		if bet.Type == "" {
-			return fmt.Errorf("bet[%d].type is required", i)
+		if bet.Notation != "" {
+			if _, err := models.ParseNotation(bet.Notation); err != nil {
+				return fmt.Errorf("bet[%d].notation: %w", i, err)
+			}
+			if len(bet.Size) > 0 || len(bet.Bankers) > 0 {
+				return fmt.Errorf("bet[%d]: size and bankers cannot be combined with notation", i)
+			}
+		} else if bet.Type == "" {
+			return fmt.Errorf("bet[%d].type or notation is required", i)
 		}
 		if len(bet.Selections) == 0 {
 			return fmt.Errorf("bet[%d] must have at least one selection", i)
@@ -177,17 +174,60 @@ func validateMultiBetRequest(req *MultiBetRequest) error {
 				return fmt.Errorf("bet[%d].selection[%d]: %w", i, j, err)
 			}
 		}
-		if err := validateStakeRequest(&bet.Stake); err != nil {
+		if bet.combinatorial() {
+			if err := validateSystemStakes(bet.Stake, bet.SizeStakes); err != nil {
+				return fmt.Errorf("bet[%d].%w", i, err)
+			}
+		} else if len(bet.SizeStakes) > 0 {
+			return fmt.Errorf("bet[%d].sizeStakes: only system, banker and preset bets take sizeStakes", i)
+		} else if err := validateStakeRequests(bet.Stake); err != nil {
 			return fmt.Errorf("bet[%d].stake: %w", i, err)
 		}
+		if err := validateEachWayRequest(bet.EachWay, append(append([]SelectionRequest{}, bet.Bankers...), bet.Selections...)...); err != nil {
+			return fmt.Errorf("bet[%d].eachWay: %w", i, err)
+		}
 	}
 	return nil
 }
 
 func validateSelectionRequest(sel *SelectionRequest) error {
-	if sel.ProductID == "" {
-		return fmt.Errorf("productId is required")
+	switch sel.Type {
+	case "", models.SelectionTypeUF:
+		if sel.ProductID == "" {
+			return fmt.Errorf("productId is required")
+		}
+		if err := validateSelectionIDs(sel); err != nil {
+			return err
+		}
+		if err := validateUOFIDs(sel.EventID, sel.Specifiers, sel.OutcomeID); err != nil {
+			return err
+		}
+	case models.SelectionTypeExternal:
+		if err := validateSelectionIDs(sel); err != nil {
+			return err
+		}
+	case models.SelectionTypeCustomBet:
+		if err := validateCustomBetRequest(sel); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("type must be '%s', '%s' or '%s'", models.SelectionTypeUF, models.SelectionTypeExternal, models.SelectionTypeCustomBet)
+	}
+	if sel.Odds == "" {
+		return fmt.Errorf("odds is required")
+	}
+	// Validate odds in the requested format
+	format, err := odds.ParseFormat(sel.OddsFormat)
+	if err != nil {
+		return fmt.Errorf("oddsFormat: %w", err)
+	}
+	if _, err := odds.Parse(sel.Odds, format); err != nil {
+		return fmt.Errorf("odds must be valid %s odds: %w", format, err)
 	}
+	return nil
+}
+
+func validateSelectionIDs(sel *SelectionRequest) error {
 	if sel.EventID == "" {
 		return fmt.Errorf("eventId is required")
 	}
@@ -197,12 +237,133 @@ func validateSelectionRequest(sel *SelectionRequest) error {
 	if sel.OutcomeID == "" {
 		return fmt.Errorf("outcomeId is required")
 	}
-	if sel.Odds == "" {
-		return fmt.Errorf("odds is required")
+	if len(sel.Legs) > 0 {
+		return fmt.Errorf("legs are only allowed for '%s' selections", models.SelectionTypeCustomBet)
+	}
+	return nil
+}
+
+// validateCustomBetRequest checks a same-game combination: the event is set
+// on the selection, the markets on its legs
+func validateCustomBetRequest(sel *SelectionRequest) error {
+	if sel.ProductID == "" {
+		return fmt.Errorf("productId is required")
+	}
+	if sel.EventID == "" {
+		return fmt.Errorf("eventId is required")
+	}
+	if _, err := uof.ParseURN(sel.EventID); err != nil {
+		return err
+	}
+	if sel.MarketID != "" || sel.OutcomeID != "" || sel.Specifiers != "" {
+		return fmt.Errorf("marketId, outcomeId and specifiers must be set on the legs of a custom bet")
+	}
+	if len(sel.Legs) < models.MinCustomBetLegs {
+		return fmt.Errorf("custom bet requires at least %d legs", models.MinCustomBetLegs)
+	}
+	for i, leg := range sel.Legs {
+		if leg.MarketID == "" {
+			return fmt.Errorf("legs[%d]: marketId is required", i)
+		}
+		if leg.OutcomeID == "" {
+			return fmt.Errorf("legs[%d]: outcomeId is required", i)
+		}
+		if err := validateUOFIDs("", leg.Specifiers, leg.OutcomeID); err != nil {
+			return fmt.Errorf("legs[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateUOFIDs checks the formats of the event URN, specifiers and outcome
+// ID of a uf selection; an empty eventId is not checked
+func validateUOFIDs(eventID, specifiers, outcomeID string) error {
+	if eventID != "" {
+		if _, err := uof.ParseURN(eventID); err != nil {
+			return err
+		}
+	}
+	if _, err := uof.ParseSpecifiers(specifiers); err != nil {
+		return err
+	}
+	return uof.ValidateOutcomeID(outcomeID)
+}
+
+// validateEachWayRequest checks the place terms, if any, and that every
+// selection still has place odds above 1. The stake itself is validated per
+// part; the place part adds the same stake again.
+func validateEachWayRequest(ew *EachWayRequest, selections ...SelectionRequest) error {
+	if ew == nil {
+		return nil
+	}
+	terms := convertEachWayRequest(ew)
+	if err := terms.Validate(); err != nil {
+		return err
+	}
+	for i, sel := range selections {
+		if sel.Type == models.SelectionTypeCustomBet {
+			return fmt.Errorf("selection[%d]: custom bets cannot be placed each-way", i)
+		}
+		if _, err := terms.PlaceOdds(decimalOdds(sel)); err != nil {
+			return fmt.Errorf("selection[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateStakeRequests checks the stake entries of a bet: each entry is
+// valid, and several entries use distinct types, one currency and one mode
+func validateStakeRequests(stakes StakeRequests) error {
+	if len(stakes) == 0 {
+		return fmt.Errorf("at least one stake is required")
+	}
+	if len(stakes) == 1 {
+		return validateStakeRequest(&stakes[0])
+	}
+	seen := make(map[string]bool, len(stakes))
+	for k, stake := range stakes {
+		if err := validateStakeRequest(&stake); err != nil {
+			return fmt.Errorf("entry %d: %w", k, err)
+		}
+		if seen[stake.Type] {
+			return fmt.Errorf("entry %d: only one '%s' stake is allowed per bet", k, stake.Type)
+		}
+		seen[stake.Type] = true
+		if stake.Currency != stakes[0].Currency {
+			return fmt.Errorf("entry %d: currency %s differs from %s", k, stake.Currency, stakes[0].Currency)
+		}
+		if stake.Mode != stakes[0].Mode {
+			return fmt.Errorf("entry %d: mode '%s' differs from '%s'", k, stake.Mode, stakes[0].Mode)
+		}
 	}
-	// Validate odds is a valid number
-	if odds, err := strconv.ParseFloat(sel.Odds, 64); err != nil || odds <= 0 {
-		return fmt.Errorf("odds must be a valid number greater than 0")
+	return nil
+}
+
+// validateSystemStakes checks the stake of a system, banker or preset bet:
+// either stake, in unit mode or as a total to split, or a unit stake per
+// combination size in sizeStakes
+func validateSystemStakes(stake StakeRequests, sizeStakes []SizeStakeRequest) error {
+	if len(sizeStakes) == 0 {
+		if err := validateStakeRequests(stake); err != nil {
+			return fmt.Errorf("stake: %w", err)
+		}
+		return nil
+	}
+	if len(stake) > 0 {
+		return fmt.Errorf("stake cannot be combined with sizeStakes")
+	}
+	seen := make(map[int]bool, len(sizeStakes))
+	for i, s := range sizeStakes {
+		if seen[s.Size] {
+			return fmt.Errorf("sizeStakes[%d]: size %d is repeated", i, s.Size)
+		}
+		seen[s.Size] = true
+		if err := validateStakeRequests(s.Stake); err != nil {
+			return fmt.Errorf("sizeStakes[%d].stake: %w", i, err)
+		}
+		if s.Stake.mode() != "unit" {
+			return fmt.Errorf("sizeStakes[%d].stake: mode must be 'unit'", i)
+		}
 	}
 	return nil
 }
@@ -211,8 +372,8 @@ func validateStakeRequest(stake *StakeRequest) error {
 	if stake.Type == "" {
 		return fmt.Errorf("type is required")
 	}
-	if stake.Type != "cash" && stake.Type != "free" {
-		return fmt.Errorf("type must be 'cash' or 'free'")
+	if stake.Type != models.StakeTypeCash && stake.Type != models.StakeTypeFree && stake.Type != models.StakeTypeBonus {
+		return fmt.Errorf("type must be 'cash', 'free' or 'bonus'")
 	}
 	if stake.Currency == "" {
 		return fmt.Errorf("currency is required")
@@ -221,9 +382,13 @@ func validateStakeRequest(stake *StakeRequest) error {
 		return fmt.Errorf("amount is required")
 	}
 	// Validate amount is a valid number
-	if amount, err := strconv.ParseFloat(stake.Amount, 64); err != nil || amount <= 0 {
+	amount, err := decimal.Parse(stake.Amount)
+	if err != nil || amount.Sign() <= 0 {
 		return fmt.Errorf("amount must be a valid number greater than 0")
 	}
+	if amount.Scale() > decimal.MTSScale {
+		return fmt.Errorf("amount must have at most %d decimal places", decimal.MTSScale)
+	}
 	if stake.Mode == "" {
 		return fmt.Errorf("mode is required")
 	}
@@ -236,16 +401,66 @@ func validateStakeRequest(stake *StakeRequest) error {
 // Conversion functions
 
 func convertSelectionRequest(req SelectionRequest) models.Selection {
+	switch req.Type {
+	case models.SelectionTypeExternal:
+		return models.NewExternalSelection(req.EventID, req.MarketID, req.OutcomeID, decimalOdds(req), req.Specifiers)
+	case models.SelectionTypeCustomBet:
+		legs := make([]models.Selection, len(req.Legs))
+		for i, leg := range req.Legs {
+			legs[i] = models.NewCustomBetLeg(leg.MarketID, leg.OutcomeID, leg.Specifiers)
+		}
+		return models.NewCustomBetSelection(req.ProductID, req.EventID, decimalOdds(req), legs...)
+	}
 	return models.NewSelection(
 		req.ProductID,
 		req.EventID,
 		req.MarketID,
 		req.OutcomeID,
-		req.Odds,
+		decimalOdds(req),
 		req.Specifiers,
 	)
 }
 
+// decimalOdds returns the selection's odds as the decimal string sent to MTS.
+// Unconvertible odds are returned as-is for the builder to reject.
+func decimalOdds(req SelectionRequest) string {
+	format, err := odds.ParseFormat(req.OddsFormat)
+	if err != nil {
+		return req.Odds
+	}
+	value, err := odds.ToDecimal(req.Odds, format)
+	if err != nil {
+		return req.Odds
+	}
+	return value
+}
+
+// responseOddsFormat reads the preferred odds format for responses from the
+// oddsFormat query parameter
+func responseOddsFormat(r *http.Request) (odds.Format, *APIError) {
+	format, err := odds.ParseFormat(r.URL.Query().Get("oddsFormat"))
+	if err != nil {
+		return "", &APIError{Code: 400, Message: "Invalid oddsFormat", Details: err.Error()}
+	}
+	return format, nil
+}
+
+func convertEachWayRequest(req *EachWayRequest) models.EachWayTerms {
+	return models.EachWayTerms{
+		Fraction:      req.Fraction,
+		Places:        req.Places,
+		PlaceMarketID: req.PlaceMarketID,
+	}
+}
+
+func convertStakeRequests(reqs StakeRequests) []models.Stake {
+	stakes := make([]models.Stake, len(reqs))
+	for i, req := range reqs {
+		stakes[i] = convertStakeRequest(req)
+	}
+	return stakes
+}
+
 func convertStakeRequest(req StakeRequest) models.Stake {
 	return models.NewStake(
 		req.Type,
@@ -48,9 +48,9 @@ func LogMTSError(endpoint, ticketID string, err error) {
 }
 
 // LogCashoutRequest logs cashout requests
-func LogCashoutRequest(cashoutID, ticketID string, cashoutType string, amount float64) {
+func LogCashoutRequest(cashoutID, ticketID string, cashoutType string, amount string) {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	log.Printf("[%s] [Cashout] Request: CashoutID=%s, TicketID=%s, Type=%s, Amount=%.2f", 
+	log.Printf("[%s] [Cashout] Request: CashoutID=%s, TicketID=%s, Type=%s, Amount=%s", 
 		timestamp, cashoutID, ticketID, cashoutType, amount)
 }
 
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+// QuoteBet handles /api/quote/{kind}: it accepts the same body as
+// /api/bets/{kind} and returns stake and potential returns without contacting MTS
+func (h *Handler) QuoteBet(w http.ResponseWriter, r *http.Request) {
+	kind := PathParam(r, "kind")
+	ticket, apiErr := h.buildTicket(kind, r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
+		return
+	}
+
+	quote, err := models.QuoteTicket(ticket)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   &APIError{Code: 400, Message: "Quote failed", Details: err.Error()},
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    quote,
+		Splits:  models.StakeSplits(ticket),
+	})
+}
+
+// ExpandLines handles /api/lines/{kind}: it accepts the same body as
+// /api/bets/{kind} and returns every line each bet settles as, with combined
+// odds, stake and potential return. Nothing is sent to MTS.
+func (h *Handler) ExpandLines(w http.ResponseWriter, r *http.Request) {
+	format, apiErr := responseOddsFormat(r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
+		return
+	}
+
+	kind := PathParam(r, "kind")
+	ticket, apiErr := h.buildTicket(kind, r)
+	if apiErr != nil {
+		respondJSON(w, apiErr.Code, APIResponse{Success: false, Error: apiErr})
+		return
+	}
+
+	bets, err := models.ExpandTicket(ticket)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   &APIError{Code: 400, Message: "Line expansion failed", Details: err.Error()},
+		})
+		return
+	}
+	models.RenderLineOdds(bets, format)
+
+	respondJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"ticketId": ticket.Content.TicketID,
+			"bets":     bets,
+		},
+	})
+}
@@ -1,25 +1,88 @@
 package api
 
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
 // Common structures for all bet types
 
 // SelectionRequest represents a selection in API requests
 type SelectionRequest struct {
-	ProductID  string  `json:"productId"`            // Product ID (e.g., "3")
-	EventID    string  `json:"eventId"`              // Event ID (e.g., "sr:match:12345")
-	MarketID   string  `json:"marketId"`             // Market ID (e.g., "1")
-	OutcomeID  string  `json:"outcomeId"`            // Outcome ID (e.g., "1712")
-	Odds       string  `json:"odds"`                 // Odds as string (e.g., "2.50")
-	Specifiers string  `json:"specifiers,omitempty"` // Optional specifiers (e.g., "hcp=1:0")
+	Type       string                `json:"type,omitempty"`       // "uf" (default), "external" or "uf-custom-bet"
+	ProductID  string                `json:"productId"`            // Product ID (e.g., "3"); optional for external selections
+	EventID    string                `json:"eventId"`              // Event ID (e.g., "sr:match:12345")
+	MarketID   string                `json:"marketId"`             // Market ID (e.g., "1"); not used for custom bets
+	OutcomeID  string                `json:"outcomeId"`            // Outcome ID (e.g., "1712"); not used for custom bets
+	Odds       string                `json:"odds"`                 // Odds as string (e.g., "2.50", "5/2", "-110")
+	OddsFormat string                `json:"oddsFormat,omitempty"` // "decimal" (default), "fractional", "american", "hongkong", "malay" or "indonesian"
+	Specifiers string                `json:"specifiers,omitempty"` // Optional specifiers (e.g., "hcp=1:0")
+	Legs       []CustomBetLegRequest `json:"legs,omitempty"`       // Markets combined by a uf-custom-bet selection
+}
+
+// CustomBetLegRequest is one market outcome of a custom bet selection
+type CustomBetLegRequest struct {
+	MarketID   string `json:"marketId"`             // Market ID (e.g., "18")
+	OutcomeID  string `json:"outcomeId"`            // Outcome ID (e.g., "12")
+	Specifiers string `json:"specifiers,omitempty"` // Optional specifiers (e.g., "total=2.5")
 }
 
 // StakeRequest represents stake information
 type StakeRequest struct {
-	Type     string  `json:"type"`     // "cash" or "free"
+	Type     string  `json:"type"`     // "cash", "free" or "bonus"
 	Currency string  `json:"currency"` // Currency code (e.g., "EUR", "USD")
 	Amount   string  `json:"amount"`   // Amount as string (e.g., "10.00")
 	Mode     string  `json:"mode"`     // "total" or "unit"
 }
 
+// StakeRequests are the stake entries of a bet, e.g. part cash and part free
+// bet. JSON accepts a single stake object or an array of them.
+type StakeRequests []StakeRequest
+
+// UnmarshalJSON accepts a stake object as well as an array of stakes
+func (s *StakeRequests) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var single StakeRequest
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*s = StakeRequests{single}
+		return nil
+	}
+	var list []StakeRequest
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// mode returns the stake mode; validation ensures all entries share it
+func (s StakeRequests) mode() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0].Mode
+}
+
+// SizeStakeRequest is the unit stake of one combination size of a system bet
+type SizeStakeRequest struct {
+	Size  int           `json:"size"`  // Combination size, e.g. 2 for the doubles
+	Stake StakeRequests `json:"stake"` // Unit stake for every line of that size
+}
+
+// EachWayRequest represents the place terms of an each-way bet.
+// The stake applies to each part, so the total staked is doubled.
+type EachWayRequest struct {
+	Fraction      string `json:"fraction"`      // Place fraction of the win odds (e.g., "1/4" or "0.25")
+	Places        int    `json:"places"`        // Number of places paid
+	PlaceMarketID string `json:"placeMarketId"` // Place market the place part is sent to
+}
+
 // ChannelRequest represents channel information
 type ChannelRequest struct {
 	Type string `json:"type"` // "internet", "mobile", "agent"
@@ -36,7 +99,8 @@ type ContextRequest struct {
 type SingleBetRequest struct {
 	TicketID  string           `json:"ticketId"`  // Unique ticket ID
 	Selection SelectionRequest `json:"selection"` // The selection
-	Stake     StakeRequest     `json:"stake"`     // Stake information
+	Stake     StakeRequests    `json:"stake"`     // Stake information
+	EachWay   *EachWayRequest  `json:"eachWay,omitempty"`
 	Context   *ContextRequest  `json:"context,omitempty"`
 }
 
@@ -44,16 +108,19 @@ type SingleBetRequest struct {
 type AccumulatorBetRequest struct {
 	TicketID   string             `json:"ticketId"`   // Unique ticket ID
 	Selections []SelectionRequest `json:"selections"` // Multiple selections (all must win)
-	Stake      StakeRequest       `json:"stake"`      // Stake information
+	Stake      StakeRequests      `json:"stake"`      // Stake information
+	EachWay    *EachWayRequest    `json:"eachWay,omitempty"`
 	Context    *ContextRequest    `json:"context,omitempty"`
 }
 
 // SystemBetRequest represents a system bet request
 type SystemBetRequest struct {
 	TicketID   string             `json:"ticketId"`   // Unique ticket ID
-	Size       []int              `json:"size"`       // Combination sizes (e.g., [2] for doubles, [2,3] for doubles and trebles)
+	Size       []int              `json:"size"`       // Combination sizes (e.g., [2] for doubles, [2,3] for doubles and trebles); optional with sizeStakes
 	Selections []SelectionRequest `json:"selections"` // Selections to combine
-	Stake      StakeRequest       `json:"stake"`      // Unit stake
+	Stake      StakeRequests      `json:"stake"`      // Unit stake, or total stake split into unit stakes
+	SizeStakes []SizeStakeRequest `json:"sizeStakes,omitempty"` // Unit stake per size instead of stake; each size becomes its own bet
+	EachWay    *EachWayRequest    `json:"eachWay,omitempty"`
 	Context    *ContextRequest    `json:"context,omitempty"`
 }
 
@@ -61,18 +128,22 @@ type SystemBetRequest struct {
 type BankerSystemBetRequest struct {
 	TicketID   string             `json:"ticketId"`   // Unique ticket ID
 	Bankers    []SelectionRequest `json:"bankers"`    // Banker selections (must be in every combination)
-	Size       []int              `json:"size"`       // Combination sizes for non-banker selections
+	Size       []int              `json:"size"`       // Combination sizes for non-banker selections; optional with sizeStakes
 	Selections []SelectionRequest `json:"selections"` // Non-banker selections to combine
-	Stake      StakeRequest       `json:"stake"`      // Unit stake
+	Stake      StakeRequests      `json:"stake"`      // Unit stake, or total stake split into unit stakes
+	SizeStakes []SizeStakeRequest `json:"sizeStakes,omitempty"` // Unit stake per size instead of stake; each size becomes its own bet
+	EachWay    *EachWayRequest    `json:"eachWay,omitempty"`
 	Context    *ContextRequest    `json:"context,omitempty"`
 }
 
 // PresetSystemBetRequest represents a preset system bet request (Trixie, Yankee, etc.)
 type PresetSystemBetRequest struct {
 	TicketID   string             `json:"ticketId"`   // Unique ticket ID
-	Type       string             `json:"type"`       // "trixie", "patent", "yankee", "lucky15", "lucky31", "lucky63", "super_yankee", "heinz", "super_heinz", "goliath"
-	Selections []SelectionRequest `json:"selections"` // Selections (count must match type requirement)
-	Stake      StakeRequest       `json:"stake"`      // Unit stake
+	Type       string             `json:"type"`       // A preset name or alias from GET /api/presets, e.g. "yankee" or "full_cover"
+	Selections []SelectionRequest `json:"selections"` // Selections (count must match the preset)
+	Stake      StakeRequests      `json:"stake"`      // Unit stake, or total stake split into unit stakes
+	SizeStakes []SizeStakeRequest `json:"sizeStakes,omitempty"` // Unit stake per size instead of stake; each size becomes its own bet
+	EachWay    *EachWayRequest    `json:"eachWay,omitempty"`
 	Context    *ContextRequest    `json:"context,omitempty"`
 }
 
@@ -85,11 +156,15 @@ type MultiBetRequest struct {
 
 // BetDefinition represents a single bet in a multi-bet ticket
 type BetDefinition struct {
-	Type       string             `json:"type"`                 // "single", "accumulator", "system", "banker_system", or preset type
+	BetID      string             `json:"betId,omitempty"`      // Optional; generated as "<ticketId>-<n>" when empty
+	Type       string             `json:"type,omitempty"`       // "single", "accumulator", "system", "banker_system", or preset type
+	Notation   string             `json:"notation,omitempty"`   // Replaces type, size and bankers, e.g. "2,3/5" or "B1+2/4" (bankers first in selections)
 	Selections []SelectionRequest `json:"selections"`           // Selections for this bet
-	Stake      StakeRequest       `json:"stake"`                // Stake for this bet
+	Stake      StakeRequests      `json:"stake"`                // Stake for this bet
 	Size       []int              `json:"size,omitempty"`       // For system bets
+	SizeStakes []SizeStakeRequest `json:"sizeStakes,omitempty"` // For system, banker and preset bets: unit stake per size instead of stake
 	Bankers    []SelectionRequest `json:"bankers,omitempty"`    // For banker system bets
+	EachWay    *EachWayRequest    `json:"eachWay,omitempty"`    // Place terms for an each-way bet
 }
 
 // CashoutRequest represents a cashout request
@@ -104,6 +179,20 @@ type CashoutRequest struct {
 	Payout          []PayoutRequest `json:"payout"`  // Payout information
 }
 
+// AcceptReofferRequest accepts the alternative stake offered for a rejected ticket
+type AcceptReofferRequest struct {
+	TicketID string `json:"ticketId"` // The rejected ticket
+}
+
+// CashoutValueRequest asks for a local valuation of a placed ticket
+type CashoutValueRequest struct {
+	TicketID   string                     `json:"ticketId"`
+	Bets       []models.Bet               `json:"bets"`              // Bets as placed, from the ticket-placement request
+	BetDetails []models.CashoutBetDetail  `json:"betDetails"`        // From the cashout-build reply
+	Cashout    *models.CashoutAmountInfo  `json:"cashout,omitempty"` // MTS offer to compare against
+	Margin     string                     `json:"margin,omitempty"`  // Overrides CASHOUT_MARGIN
+}
+
 // PayoutRequest represents payout information
 type PayoutRequest struct {
 	Type     string  `json:"type"`     // "cash" or "free"
@@ -114,14 +203,20 @@ type PayoutRequest struct {
 
 // APIResponse represents a standard API response
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   *APIError   `json:"error,omitempty"`
+	Success  bool                   `json:"success"`
+	Data     interface{}            `json:"data,omitempty"`
+	Issues   []models.Issue         `json:"issues,omitempty"`   // Explained MTS codes of a ticket response
+	Reoffer  *models.Reoffer        `json:"reoffer,omitempty"`  // Alternative stake offer, accepted via /api/reoffer/accept
+	Currency *currency.TicketRecord `json:"currency,omitempty"` // Stake in the settlement currency and the rates MTS applied
+	Bets     []models.BetMapping    `json:"bets,omitempty"`     // Request index and type of each bet in the response
+	Splits   []models.StakeSplit    `json:"stakeSplits,omitempty"` // Total stakes converted into unit stakes, with the remainder
+	Error    *APIError              `json:"error,omitempty"`
 }
 
 // APIError represents an error in API response
 type APIError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Details string                 `json:"details,omitempty"`
+	Errors  []*models.BuilderError `json:"errors,omitempty"` // Per-field errors, e.g. correlated selections to highlight
 }
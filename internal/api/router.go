@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in both directions: a client may
+// send its own, otherwise one is generated
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs
+const maxRequestIDLength = 128
+
+// Middleware wraps a handler, e.g. to log or recover from panics
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler in the middleware; the first one runs outermost
+func Chain(handler http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Router dispatches requests by method and path. Patterns are slash-separated
+// segments where "{name}" matches any one segment, read with PathParam. A
+// path that matches no pattern is answered with 404, one that matches only
+// under other methods with 405 and an Allow header, both in the APIResponse
+// envelope. GET routes also answer HEAD. Middleware added with Use runs for
+// every request, including those answered with 404 or 405.
+type Router struct {
+	routes     []*route
+	middleware []Middleware
+}
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  http.Handler
+	aliasOf  string // The pattern this one is a compatibility alias of
+}
+
+// RouteInfo describes a registered route for the API index
+type RouteInfo struct {
+	Method  string   `json:"method"`
+	Path    string   `json:"path"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type pathParamsKey struct{}
+type requestIDKey struct{}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Use appends middleware to the chain every request passes through
+func (rt *Router) Use(middleware ...Middleware) {
+	rt.middleware = append(rt.middleware, middleware...)
+}
+
+// Handle registers a handler for a method and pattern. Aliases are further
+// patterns served by the same handler, listed under the pattern in Routes.
+func (rt *Router) Handle(method, pattern string, handler http.HandlerFunc, aliases ...string) {
+	rt.add(method, pattern, handler, "")
+	for _, alias := range aliases {
+		rt.add(method, alias, handler, pattern)
+	}
+}
+
+func (rt *Router) add(method, pattern string, handler http.Handler, aliasOf string) {
+	for _, existing := range rt.routes {
+		if existing.method == method && existing.pattern == pattern {
+			panic(fmt.Sprintf("api: route %s %s registered twice", method, pattern))
+		}
+	}
+	rt.routes = append(rt.routes, &route{
+		method:   method,
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		handler:  handler,
+		aliasOf:  aliasOf,
+	})
+}
+
+// Routes lists the registered routes in registration order, aliases folded
+// into the route they stand for
+func (rt *Router) Routes() []RouteInfo {
+	var infos []RouteInfo
+	index := make(map[string]int)
+	for _, r := range rt.routes {
+		if r.aliasOf == "" {
+			index[r.method+" "+r.pattern] = len(infos)
+			infos = append(infos, RouteInfo{Method: r.method, Path: r.pattern})
+			continue
+		}
+		if i, ok := index[r.method+" "+r.aliasOf]; ok {
+			infos[i].Aliases = append(infos[i].Aliases, r.pattern)
+		}
+	}
+	return infos
+}
+
+// ServeHTTP runs the middleware chain and dispatches the request
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Chain(http.HandlerFunc(rt.dispatch), rt.middleware...).ServeHTTP(w, r)
+}
+
+func (rt *Router) dispatch(w http.ResponseWriter, r *http.Request) {
+	segments := splitPath(r.URL.Path)
+	var allowed []string
+	for _, route := range rt.routes {
+		params, ok := matchSegments(route.segments, segments)
+		if !ok {
+			continue
+		}
+		if route.method != r.Method && !(r.Method == http.MethodHead && route.method == http.MethodGet) {
+			allowed = append(allowed, route.method)
+			continue
+		}
+		if len(params) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params))
+		}
+		route.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		respondError(w, http.StatusMethodNotAllowed, "Method not allowed",
+			fmt.Errorf("%s is not allowed on %s; use %s", r.Method, r.URL.Path, strings.Join(allowed, " or ")))
+		return
+	}
+	respondError(w, http.StatusNotFound, "Not found", fmt.Errorf("no endpoint at %s", r.URL.Path))
+}
+
+// PathParam returns the value of a "{name}" segment of the matched pattern
+func PathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
+
+// splitPath splits a path into segments, ignoring a trailing slash
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func matchSegments(pattern, path []string) (map[string]string, bool) {
+	if len(pattern) != len(path) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if path[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[seg[1:len(seg)-1]] = path[i]
+			continue
+		}
+		if seg != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// RequestID is middleware that assigns each request an ID, taken from the
+// X-Request-ID header when the client sent a usable one. The ID is echoed in
+// the response header and available to handlers via RequestIDFromContext.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFromContext returns the ID RequestID assigned, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog is middleware that logs each request with its status, duration
+// and request ID
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("[HTTP] %s %s -> %d (%s) request=%s",
+			r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Microsecond), RequestIDFromContext(r.Context()))
+	})
+}
+
+// Recover is middleware that turns a handler panic into a 500 response
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("[HTTP] panic serving %s %s (request=%s): %v", r.Method, r.URL.Path, RequestIDFromContext(r.Context()), p)
+				respondError(w, http.StatusInternalServerError, "Internal server error", fmt.Errorf("request %s failed", RequestIDFromContext(r.Context())))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the status written by a handler. It passes
+// Hijack through so WebSocket upgrades still work behind AccessLog.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	s.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testRouter() *Router {
+	rt := NewRouter()
+	rt.Use(RequestID)
+	rt.Handle(http.MethodPost, "/api/v1/quote/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: PathParam(r, "kind")})
+	}, "/api/quote/{kind}")
+	rt.Handle(http.MethodGet, "/api/v1/presets", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: RequestIDFromContext(r.Context())})
+	})
+	return rt
+}
+
+func serve(rt *Router, method, path string, header http.Header) (*httptest.ResponseRecorder, APIResponse) {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v[0])
+	}
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+	var resp APIResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec, resp
+}
+
+func TestRouterMatchesMethodAndPath(t *testing.T) {
+	rt := testRouter()
+
+	for _, path := range []string{"/api/v1/quote/system", "/api/quote/system", "/api/v1/quote/system/"} {
+		rec, resp := serve(rt, http.MethodPost, path, nil)
+		if rec.Code != http.StatusOK || resp.Data != "system" {
+			t.Errorf("POST %s: expected kind system, got %d %+v", path, rec.Code, resp)
+		}
+	}
+
+	rec, resp := serve(rt, http.MethodGet, "/api/v1/quote/system", nil)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "POST" {
+		t.Errorf("Expected 405 with Allow: POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if resp.Success || resp.Error == nil || resp.Error.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected a 405 error envelope, got %+v", resp)
+	}
+
+	for _, path := range []string{"/api/v1/quote", "/api/v1/quote/system/extra", "/api/v2/presets"} {
+		rec, resp := serve(rt, http.MethodPost, path, nil)
+		if rec.Code != http.StatusNotFound || resp.Error == nil || resp.Error.Code != http.StatusNotFound {
+			t.Errorf("%s: expected a 404 error envelope, got %d %+v", path, rec.Code, resp)
+		}
+	}
+
+	if rec, _ := serve(rt, http.MethodHead, "/api/v1/presets", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected HEAD to be served by the GET route, got %d", rec.Code)
+	}
+
+	routes := rt.Routes()
+	if len(routes) != 2 || len(routes[0].Aliases) != 1 || routes[0].Aliases[0] != "/api/quote/{kind}" {
+		t.Errorf("Unexpected routes %+v", routes)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	rt := testRouter()
+
+	rec, resp := serve(rt, http.MethodGet, "/api/v1/presets", http.Header{RequestIDHeader: {"client-42"}})
+	if rec.Header().Get(RequestIDHeader) != "client-42" || resp.Data != "client-42" {
+		t.Errorf("Expected the client request ID to be kept, got %q / %v", rec.Header().Get(RequestIDHeader), resp.Data)
+	}
+
+	rec, resp = serve(rt, http.MethodGet, "/api/v1/presets", http.Header{RequestIDHeader: {"bad id"}})
+	id := rec.Header().Get(RequestIDHeader)
+	if id == "" || id == "bad id" || resp.Data != id {
+		t.Errorf("Expected a generated request ID, got %q / %v", id, resp.Data)
+	}
+
+	// Errors raised by the router carry an ID too
+	if rec, _ := serve(rt, http.MethodGet, "/missing", nil); rec.Header().Get(RequestIDHeader) == "" {
+		t.Error("Expected a request ID on a 404 response")
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import "net/http"
+
+// APIPrefix is the path prefix of the versioned API
+const APIPrefix = "/api/v1"
+
+// ServiceVersion is reported by the API index
+const ServiceVersion = "2.0.0"
+
+// websocketPath is where the WebSocket endpoint is mounted when one is given
+const websocketPath = "/ws"
+
+// NewAPIRouter registers every endpoint under /api/v1, with the paths used
+// before versioning kept as aliases. websocket, if not nil, is served at /ws.
+// Requests get an ID, are logged and are recovered from panics.
+func NewAPIRouter(h *Handler, websocket http.HandlerFunc) *Router {
+	rt := NewRouter()
+	rt.Use(RequestID, AccessLog, Recover)
+
+	routes := []struct {
+		method  string
+		path    string // Under APIPrefix
+		alias   string
+		handler http.HandlerFunc
+	}{
+		{http.MethodGet, "/health", "/health", h.HealthCheck},
+		{http.MethodPost, "/tickets", "/api/tickets", h.PlaceTicket},
+		{http.MethodPost, "/bets/single", "/api/bets/single", h.PlaceSingleBet},
+		{http.MethodPost, "/bets/accumulator", "/api/bets/accumulator", h.PlaceAccumulatorBet},
+		{http.MethodPost, "/bets/system", "/api/bets/system", h.PlaceSystemBet},
+		{http.MethodPost, "/bets/banker-system", "/api/bets/banker-system", h.PlaceBankerSystemBet},
+		{http.MethodPost, "/bets/preset", "/api/bets/preset", h.PlacePresetSystemBet},
+		{http.MethodPost, "/bets/multi", "/api/bets/multi", h.PlaceMultiBet},
+		{http.MethodGet, "/presets", "/api/presets", h.ListPresets},
+		{http.MethodPost, "/reoffer/accept", "/api/reoffer/accept", h.AcceptReoffer},
+		{http.MethodPost, "/quote/{kind}", "/api/quote/{kind}", h.QuoteBet},
+		{http.MethodPost, "/lines/{kind}", "/api/lines/{kind}", h.ExpandLines},
+		{http.MethodPost, "/cashout", "/api/cashout", h.RequestCashout},
+		{http.MethodPost, "/cashout/value", "/api/cashout/value", h.ValueCashout},
+	}
+	for _, r := range routes {
+		rt.Handle(r.method, APIPrefix+r.path, r.handler, r.alias)
+	}
+	if websocket != nil {
+		rt.Handle(http.MethodGet, websocketPath, websocket)
+	}
+
+	index := apiIndex(rt, websocket != nil)
+	rt.Handle(http.MethodGet, APIPrefix, index, "/")
+	return rt
+}
+
+// apiIndex serves the service name, version and registered routes
+func apiIndex(rt *Router, websocket bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc := map[string]interface{}{
+			"service":   "mts-service",
+			"version":   ServiceVersion,
+			"api":       APIPrefix,
+			"endpoints": rt.Routes(),
+		}
+		if websocket {
+			doc["websocket"] = websocketPath + "?userId=<userId>&token=<token>"
+		}
+		respondJSON(w, http.StatusOK, doc)
+	}
+}
@@ -5,7 +5,13 @@ import (
 	"os"
 	"strconv"
 	"log"
+	"time"
 	"github.com/gdsZyy/mts-service/internal/client"
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/limits"
+	"github.com/gdsZyy/mts-service/internal/models"
+	"github.com/gdsZyy/mts-service/internal/schema"
 )
 
 type Config struct {
@@ -23,6 +29,23 @@ type Config struct {
 			WSAudience   string
 		AccessToken  string // Optional: UOF Access Token for whoami.xml
 		Production   bool
+		SchemaValidation string // "off", "log" or "enforce" for outgoing MTS messages
+		OfflineQueueSize int           // Max requests held while disconnected (0 disables the queue)
+		OfflineQueueTTL  time.Duration // Default time a queued request waits for reconnect
+		ReofferTTL       time.Duration // How long an alternative stake offer can be accepted
+
+	// Cashout valuation
+		CashoutMargin        string // Share of the fair value withheld from suggested cashouts, e.g. "0.05"
+		CashoutRoundingScale int    // Decimal places of suggested cashout amounts
+		CashoutRoundingMode  string // decimal.ParseRoundingMode name, e.g. "down"
+
+	// Currencies
+		CurrencyFile string             // JSON currency registry; empty accepts any well-formed code
+		Currencies   *currency.Registry // Loaded from CurrencyFile
+
+	// Local betting limits, checked before tickets are sent
+		LimitsFile string         // JSON limits file; empty disables the checks
+		Limits     *limits.Limits // Loaded from LimitsFile, nil when disabled
 
 	// OAuth
 		AuthURL string
@@ -42,6 +65,15 @@ func Load() (*Config, error) {
 				WSAudience:   getEnv("MTS_WS_AUDIENCE", "mbs-dp-non-prod-wss"),
 			AccessToken:  getEnv("UOF_ACCESS_TOKEN", ""),
 		Production:   getEnvBool("MTS_PRODUCTION", false),
+		SchemaValidation: getEnv("MTS_SCHEMA_VALIDATION", "log"),
+		OfflineQueueSize: int(getEnvInt64("MTS_OFFLINE_QUEUE_SIZE", 0)),
+		OfflineQueueTTL:  time.Duration(getEnvInt64("MTS_OFFLINE_QUEUE_TTL_SECONDS", 15)) * time.Second,
+		ReofferTTL:       time.Duration(getEnvInt64("MTS_REOFFER_TTL_SECONDS", 30)) * time.Second,
+		CashoutMargin:        getEnv("CASHOUT_MARGIN", "0"),
+		CashoutRoundingScale: int(getEnvInt64("CASHOUT_ROUNDING_SCALE", 2)),
+		CashoutRoundingMode:  getEnv("CASHOUT_ROUNDING_MODE", "down"),
+		CurrencyFile:         getEnv("CURRENCY_FILE", ""),
+		LimitsFile:           getEnv("LIMITS_FILE", ""),
 				AuthURL:      getEnv("MTS_AUTH_URL", "https://auth.sportradar.com/oauth/token"),
 			UOFAPIBaseURL: getEnv("UOF_API_BASE_URL", "https://global.api.betradar.com"),
 		}
@@ -75,6 +107,30 @@ func Load() (*Config, error) {
 		log.Printf("Bookmaker Info fetched successfully: BookmakerID=%s, VirtualHost=%s", cfg.BookmakerID, cfg.VirtualHost)
 	}
 
+	if _, err := cfg.CashoutRules(); err != nil {
+		return nil, err
+	}
+	if _, err := cfg.SchemaMode(); err != nil {
+		return nil, err
+	}
+
+	cfg.Currencies = currency.Default()
+	if cfg.CurrencyFile != "" {
+		registry, err := currency.Load(cfg.CurrencyFile)
+		if err != nil {
+			return nil, fmt.Errorf("CURRENCY_FILE: %w", err)
+		}
+		cfg.Currencies = registry
+	}
+
+	if cfg.LimitsFile != "" {
+		checks, err := limits.Load(cfg.LimitsFile)
+		if err != nil {
+			return nil, fmt.Errorf("LIMITS_FILE: %w", err)
+		}
+		cfg.Limits = checks
+	}
+
 	// Final check for required fields
 	if cfg.BookmakerID == "" {
 		return nil, fmt.Errorf("MTS_BOOKMAKER_ID is required and could not be fetched")
@@ -86,6 +142,31 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// CashoutRules parses the cashout valuation settings
+func (c *Config) CashoutRules() (models.CashoutRules, error) {
+	margin, err := decimal.Parse(c.CashoutMargin)
+	if err != nil || margin.Sign() < 0 || !margin.LessThan(decimal.One) {
+		return models.CashoutRules{}, fmt.Errorf("CASHOUT_MARGIN must be a number from 0 to below 1, got %q", c.CashoutMargin)
+	}
+	if c.CashoutRoundingScale < 0 || c.CashoutRoundingScale > decimal.MTSScale {
+		return models.CashoutRules{}, fmt.Errorf("CASHOUT_ROUNDING_SCALE must be between 0 and %d", decimal.MTSScale)
+	}
+	mode, err := decimal.ParseRoundingMode(c.CashoutRoundingMode)
+	if err != nil {
+		return models.CashoutRules{}, fmt.Errorf("CASHOUT_ROUNDING_MODE: %w", err)
+	}
+	return models.CashoutRules{Margin: margin, Scale: c.CashoutRoundingScale, Rounding: mode}, nil
+}
+
+// SchemaMode parses the validation mode for outgoing MTS messages
+func (c *Config) SchemaMode() (schema.Mode, error) {
+	mode, err := schema.ParseMode(c.SchemaValidation)
+	if err != nil {
+		return "", fmt.Errorf("MTS_SCHEMA_VALIDATION: %w", err)
+	}
+	return mode, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
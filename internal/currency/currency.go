@@ -0,0 +1,289 @@
+// Package currency holds the currencies the service accepts: their precision,
+// stake limits and rate into the operator's settlement currency.
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+// codePattern is the currency code format MTS accepts, e.g. "EUR" or "mBTC"
+var codePattern = regexp.MustCompile(`^[A-Za-z0-9]{3,4}$`)
+
+// Currency describes one accepted currency
+type Currency struct {
+	Code     string            `json:"code"`               // As sent to MTS, e.g. "EUR" or "mBTC"
+	Decimals int               `json:"decimals"`           // Decimal places accepted in amounts
+	MinStake *decimal.Decimal  `json:"minStake,omitempty"` // Smallest stake per line
+	MaxStake *decimal.Decimal  `json:"maxStake,omitempty"` // Largest total stake of a bet
+	Rate     *decimal.Decimal  `json:"rate,omitempty"`     // Value of one unit in the settlement currency
+	BetTypes map[string]Limits `json:"betTypes,omitempty"` // Overrides by bet kind, e.g. "system"
+}
+
+// Limits are the stake limits of a currency for one bet kind
+type Limits struct {
+	MinStake *decimal.Decimal `json:"minStake,omitempty"`
+	MaxStake *decimal.Decimal `json:"maxStake,omitempty"`
+}
+
+// Registry is the set of accepted currencies. The default registry accepts any
+// well-formed code at MTS precision; a registry read from a file accepts only
+// the currencies it lists.
+type Registry struct {
+	settlement string
+	currencies map[string]*Currency
+	byFold     map[string]string // Lower-case code to listed code
+}
+
+// file is the JSON layout of a currency file
+type file struct {
+	SettlementCurrency string     `json:"settlementCurrency"`
+	Currencies         []Currency `json:"currencies"`
+}
+
+// Default returns the registry used when no currency file is configured
+func Default() *Registry {
+	return &Registry{}
+}
+
+// Load reads a currency file
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read currency file: %w", err)
+	}
+	registry, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return registry, nil
+}
+
+// Parse reads a currency file's contents, e.g.
+//
+//	{"settlementCurrency": "EUR", "currencies": [
+//	  {"code": "EUR", "decimals": 2, "minStake": "0.1", "maxStake": "10000", "rate": "1"},
+//	  {"code": "mBTC", "decimals": 5, "rate": "60.5", "betTypes": {"system": {"minStake": "0.01"}}}]}
+func Parse(data []byte) (*Registry, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid currency file: %w", err)
+	}
+	if len(f.Currencies) == 0 {
+		return nil, fmt.Errorf("no currencies listed")
+	}
+
+	r := &Registry{
+		currencies: make(map[string]*Currency, len(f.Currencies)),
+		byFold:     make(map[string]string, len(f.Currencies)),
+	}
+	for i := range f.Currencies {
+		c := &f.Currencies[i]
+		if !codePattern.MatchString(c.Code) {
+			return nil, fmt.Errorf("currencies[%d]: invalid code %q", i, c.Code)
+		}
+		if _, ok := r.byFold[strings.ToLower(c.Code)]; ok {
+			return nil, fmt.Errorf("currencies[%d]: %s is listed twice", i, c.Code)
+		}
+		if c.Decimals < 0 || c.Decimals > decimal.MTSScale {
+			return nil, fmt.Errorf("%s: decimals must be between 0 and %d", c.Code, decimal.MTSScale)
+		}
+		if c.Rate != nil && c.Rate.Sign() <= 0 {
+			return nil, fmt.Errorf("%s: rate must be greater than 0", c.Code)
+		}
+		if err := checkLimits(Limits{c.MinStake, c.MaxStake}); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Code, err)
+		}
+		for kind, limits := range c.BetTypes {
+			if err := checkLimits(limits); err != nil {
+				return nil, fmt.Errorf("%s: betTypes.%s: %w", c.Code, kind, err)
+			}
+		}
+		r.currencies[c.Code] = c
+		r.byFold[strings.ToLower(c.Code)] = c.Code
+	}
+
+	if f.SettlementCurrency != "" {
+		settlement, err := r.Lookup(f.SettlementCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("settlementCurrency: %w", err)
+		}
+		r.settlement = settlement.Code
+		if settlement.Rate == nil {
+			one := decimal.One
+			settlement.Rate = &one
+		}
+	}
+	return r, nil
+}
+
+func checkLimits(l Limits) error {
+	if l.MinStake != nil && l.MinStake.Sign() < 0 {
+		return fmt.Errorf("minStake must not be negative")
+	}
+	if l.MaxStake != nil && l.MaxStake.Sign() <= 0 {
+		return fmt.Errorf("maxStake must be greater than 0")
+	}
+	if l.MinStake != nil && l.MaxStake != nil && l.MaxStake.LessThan(*l.MinStake) {
+		return fmt.Errorf("maxStake is below minStake")
+	}
+	return nil
+}
+
+// Settlement returns the operator's settlement currency, or "" if none is configured
+func (r *Registry) Settlement() string {
+	return r.settlement
+}
+
+// Lookup resolves a currency code. Codes match case-insensitively when only
+// the case differs from a listed one; the default registry upper-cases
+// three-letter ISO 4217 codes and keeps other codes, such as "mBTC", as given.
+func (r *Registry) Lookup(code string) (*Currency, error) {
+	if r.currencies == nil {
+		if !codePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid currency code %q", code)
+		}
+		if len(code) == 3 {
+			code = strings.ToUpper(code)
+		}
+		return &Currency{Code: code, Decimals: decimal.MTSScale}, nil
+	}
+	if c, ok := r.currencies[code]; ok {
+		return c, nil
+	}
+	if listed, ok := r.byFold[strings.ToLower(code)]; ok {
+		return r.currencies[listed], nil
+	}
+	return nil, fmt.Errorf("unsupported currency %q", code)
+}
+
+// Limits returns the stake limits of the currency for a bet kind
+func (c *Currency) Limits(kind string) Limits {
+	limits := Limits{MinStake: c.MinStake, MaxStake: c.MaxStake}
+	if override, ok := c.BetTypes[kind]; ok {
+		if override.MinStake != nil {
+			limits.MinStake = override.MinStake
+		}
+		if override.MaxStake != nil {
+			limits.MaxStake = override.MaxStake
+		}
+	}
+	return limits
+}
+
+// Amount parses an amount in the currency. It must be greater than 0 and have
+// no more decimal places than the currency allows; trailing zeros are ignored.
+func (c *Currency) Amount(amount string) (decimal.Decimal, error) {
+	value, err := decimal.Parse(amount)
+	if err != nil || value.Sign() <= 0 {
+		return decimal.Decimal{}, fmt.Errorf("amount %q must be a valid number greater than 0", amount)
+	}
+	value = value.Normalize()
+	if value.Scale() > c.Decimals {
+		return decimal.Decimal{}, fmt.Errorf("amount %q has more than %d decimal places for %s", amount, c.Decimals, c.Code)
+	}
+	return value, nil
+}
+
+// NormalizeAmount resolves a currency code and checks an amount in it, e.g. of
+// a cashout payout, returning both in the form sent to MTS
+func (r *Registry) NormalizeAmount(code, amount string) (string, string, error) {
+	c, err := r.Lookup(code)
+	if err != nil {
+		return "", "", err
+	}
+	value, err := c.Amount(amount)
+	if err != nil {
+		return "", "", err
+	}
+	return c.Code, value.StringMTS(), nil
+}
+
+// ToSettlement converts an amount into the settlement currency, rounded
+// half-up to the settlement currency's decimal places
+func (r *Registry) ToSettlement(amount decimal.Decimal, code string) (decimal.Decimal, error) {
+	if r.settlement == "" {
+		return decimal.Decimal{}, fmt.Errorf("no settlement currency configured")
+	}
+	c, err := r.Lookup(code)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if c.Rate == nil {
+		return decimal.Decimal{}, fmt.Errorf("no rate configured for %s", c.Code)
+	}
+	return amount.Mul(*c.Rate).Round(r.currencies[r.settlement].Decimals, decimal.RoundHalfUp), nil
+}
+
+// CheckTicket validates the stakes of a built ticket and normalises them in
+// place: codes take their registered spelling and amounts lose trailing zeros.
+// Each bet is checked against the limits for its kind; the minimum applies
+// to the stake per line and the maximum to the bet's total stake. Problems are
+// returned as models.BuilderErrors naming the stake field.
+func (r *Registry) CheckTicket(ticket *models.TicketRequest) error {
+	var errs models.BuilderErrors
+	fail := func(index int, field, format string, args ...interface{}) {
+		errs = append(errs, &models.BuilderError{BetIndex: index, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i := range ticket.Content.Bets {
+		bet := &ticket.Content.Bets[i]
+		var c *Currency
+		total := decimal.Zero
+		valid := true
+		for k := range bet.Stake {
+			stake := &bet.Stake[k]
+			field := "stake"
+			if len(bet.Stake) > 1 {
+				field = fmt.Sprintf("stake[%d]", k)
+			}
+			sc, err := r.Lookup(stake.Currency)
+			if err != nil {
+				fail(i, field+".currency", "%v", err)
+				valid = false
+				continue
+			}
+			stake.Currency, c = sc.Code, sc
+			amount, err := sc.Amount(stake.Amount)
+			if err != nil {
+				fail(i, field+".amount", "%v", err)
+				valid = false
+				continue
+			}
+			stake.Amount = amount.StringMTS()
+			total = total.Add(amount)
+		}
+		if !valid || c == nil {
+			continue
+		}
+
+		lines, err := models.CountLines(*bet)
+		if err != nil || lines == 0 {
+			continue
+		}
+		perLine := total
+		if bet.Stake[0].Mode == "unit" {
+			total = total.MulInt(lines)
+		} else {
+			perLine = total.Div(decimal.NewFromInt(lines), decimal.MTSScale, decimal.RoundDown)
+		}
+		kind := bet.Kind()
+		limits := c.Limits(kind)
+		if limits.MinStake != nil && perLine.LessThan(*limits.MinStake) {
+			fail(i, "stake", "stake per line %s is below the %s minimum of %s %s", perLine.StringMTS(), kind, limits.MinStake.StringMTS(), c.Code)
+		}
+		if limits.MaxStake != nil && total.GreaterThan(*limits.MaxStake) {
+			fail(i, "stake", "total stake %s exceeds the %s maximum of %s %s", total.StringMTS(), kind, limits.MaxStake.StringMTS(), c.Code)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
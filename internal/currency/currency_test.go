@@ -0,0 +1,101 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+const testFile = `{
+  "settlementCurrency": "EUR",
+  "currencies": [
+    {"code": "EUR", "decimals": 2, "minStake": "0.10", "maxStake": "100"},
+    {"code": "mBTC", "decimals": 5, "rate": "60.5", "betTypes": {"system": {"maxStake": "5"}}}
+  ]
+}`
+
+func TestCheckTicketNormalisesAndLimits(t *testing.T) {
+	registry, err := Parse([]byte(testFile))
+	if err != nil {
+		t.Fatalf("Failed to parse registry: %v", err)
+	}
+
+	sels := []models.Selection{
+		models.NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		models.NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		models.NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+	}
+	ticket, err := models.NewTicketBuilder(45426, "currency-001").
+		AddSingleBet(sels[0], models.NewStake("cash", "eur", "10.50", "total")).
+		AddSystemBet([]int{2}, sels, models.NewStake("cash", "MBTC", "2", "unit")).
+		AddSingleBet(sels[1], models.NewStake("cash", "EUR", "0.05", "total")).
+		AddSingleBet(sels[2], models.NewStake("cash", "EUR", "1.005", "total")).
+		AddSingleBet(sels[2], models.NewStake("cash", "USD", "1", "total")).
+		Build("corr-currency")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	err = registry.CheckTicket(ticket)
+	errs, ok := err.(models.BuilderErrors)
+	if !ok {
+		t.Fatalf("Expected BuilderErrors, got %v", err)
+	}
+	expected := map[int]string{1: "stake", 2: "stake", 3: "stake.amount", 4: "stake.currency"}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errs)
+	}
+	for _, e := range errs {
+		if expected[e.BetIndex] != e.Field {
+			t.Errorf("Unexpected error %v", e)
+		}
+	}
+
+	stake := ticket.Content.Bets[0].Stake[0]
+	if stake.Currency != "EUR" || stake.Amount != "10.5" {
+		t.Errorf("Expected EUR 10.5, got %s %s", stake.Currency, stake.Amount)
+	}
+	if ticket.Content.Bets[1].Stake[0].Currency != "mBTC" {
+		t.Errorf("Expected the listed spelling mBTC, got %s", ticket.Content.Bets[1].Stake[0].Currency)
+	}
+}
+
+func TestSettlementAndRecord(t *testing.T) {
+	registry, _ := Parse([]byte(testFile))
+
+	converted, err := registry.ToSettlement(decimal.MustParse("0.12345"), "mBTC")
+	if err != nil || converted.String() != "7.47" {
+		t.Errorf("Expected 7.47 EUR, got %s, %v", converted, err)
+	}
+	if _, err := Default().ToSettlement(decimal.One, "EUR"); err == nil {
+		t.Error("Expected an error without a settlement currency")
+	}
+
+	ticket, _ := models.NewTicketBuilder(45426, "currency-002").
+		AddSingleBet(models.NewSelection("3", "sr:match:1", "1", "1", "2.00"), models.NewStake("cash", "mBTC", "1", "total")).
+		Build("corr")
+	rates := []models.ExchangeRate{{FromCurrency: "mBTC", ToCurrency: "EUR", Rate: "60.40000000"}}
+	record := registry.Record(ticket, &models.TicketResponse{Content: models.TicketResponseContent{ExchangeRate: rates}})
+	if record == nil || record.SettlementCurrency != "EUR" || record.SettlementStake != "60.50" || len(record.ExchangeRates) != 1 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	registry := Default()
+	for code, want := range map[string]string{"eur": "EUR", "mBTC": "mBTC", "USDT": "USDT"} {
+		c, err := registry.Lookup(code)
+		if err != nil || c.Code != want {
+			t.Errorf("Lookup(%q) = %v, %v; expected %s", code, c, err, want)
+		}
+	}
+	for _, code := range []string{"", "EU", "EURO1", "E-R"} {
+		if _, err := registry.Lookup(code); err == nil {
+			t.Errorf("Expected %q to be rejected", code)
+		}
+	}
+	if _, _, err := registry.NormalizeAmount("EUR", "1.123456789"); err == nil {
+		t.Error("Expected more than 8 decimal places to be rejected")
+	}
+}
@@ -0,0 +1,37 @@
+package currency
+
+import (
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+// TicketRecord is the currency information kept with a placed ticket: what the
+// customer staked, its value in the settlement currency and the exchange
+// rates MTS applied
+type TicketRecord struct {
+	Currency           string                `json:"currency"`
+	TotalStake         string                `json:"totalStake"`
+	SettlementCurrency string                `json:"settlementCurrency,omitempty"`
+	SettlementStake    string                `json:"settlementStake,omitempty"` // At the configured rate
+	ExchangeRates      []models.ExchangeRate `json:"exchangeRates,omitempty"`   // From the MTS ticket reply
+}
+
+// Record builds the currency record of a ticket and its MTS reply. It returns
+// nil when the ticket's stakes cannot be totalled.
+func (r *Registry) Record(ticket *models.TicketRequest, resp *models.TicketResponse) *TicketRecord {
+	quote, err := models.QuoteTicket(ticket)
+	if err != nil {
+		return nil
+	}
+	record := &TicketRecord{Currency: quote.Currency, TotalStake: quote.TotalStake}
+	if resp != nil {
+		record.ExchangeRates = resp.Content.ExchangeRate
+	}
+	if total, err := decimal.Parse(quote.TotalStake); err == nil {
+		if converted, err := r.ToSettlement(total, quote.Currency); err == nil {
+			record.SettlementCurrency = r.settlement
+			record.SettlementStake = converted.String()
+		}
+	}
+	return record
+}
@@ -0,0 +1,408 @@
+// Package decimal provides an exact fixed-point decimal type for stakes, odds
+// and payouts. Values are stored as an arbitrary-precision integer and a
+// base-10 scale, so amounts such as mBTC stakes never pass through float64.
+package decimal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MTSScale is the maximum number of decimal places MTS accepts for amounts
+const MTSScale = 8
+
+// RoundingMode selects how digits beyond the target scale are discarded
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // 2.345 -> 2.35, -2.345 -> -2.35
+	RoundHalfEven                     // Banker's rounding: 2.345 -> 2.34, 2.355 -> 2.36
+	RoundDown                         // Toward zero: 2.349 -> 2.34
+	RoundUp                           // Away from zero: 2.341 -> 2.35
+	RoundFloor                        // Toward negative infinity
+	RoundCeiling                      // Toward positive infinity
+)
+
+var roundingModeNames = map[string]RoundingMode{
+	"half-up":   RoundHalfUp,
+	"half-even": RoundHalfEven,
+	"down":      RoundDown,
+	"up":        RoundUp,
+	"floor":     RoundFloor,
+	"ceiling":   RoundCeiling,
+}
+
+// ParseRoundingMode resolves a rounding mode name such as "half-up" or "down"
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	if mode, ok := roundingModeNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return mode, nil
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q", name)
+}
+
+// Decimal is an immutable fixed-point number equal to unscaled × 10^-scale.
+// The zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+var (
+	Zero    = New(0, 0)
+	One     = New(1, 0)
+	Hundred = New(100, 0)
+)
+
+var bigTen = big.NewInt(10)
+
+// New returns unscaled × 10^-scale, e.g. New(1050, 2) is 10.50
+func New(unscaled int64, scale int) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// NewFromInt returns the integer value i
+func NewFromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// NewFromFloat converts a float64 using its shortest exact decimal representation,
+// so 0.1 becomes "0.1" rather than the binary approximation
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// Parse reads a plain decimal string such as "10", "-0.5" or "1.23456789".
+// Exponents, thousands separators and surrounding spaces are rejected.
+func Parse(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: empty", s)
+	}
+
+	digits := s
+	negative := false
+	switch digits[0] {
+	case '-':
+		negative = true
+		digits = digits[1:]
+	case '+':
+		digits = digits[1:]
+	}
+
+	intPart, fracPart := digits, ""
+	if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+		intPart, fracPart = digits[:dot], digits[dot+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: no digits", s)
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return Decimal{}, fmt.Errorf("invalid decimal %q: unexpected character %q", s, c)
+			}
+		}
+	}
+
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{unscaled: unscaled, scale: len(fracPart)}, nil
+}
+
+// MustParse is like Parse but panics on invalid input; intended for constants
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns d's unscaled value expressed at a larger scale
+func (d Decimal) rescale(scale int) *big.Int {
+	if scale == d.scale {
+		return new(big.Int).Set(d.int())
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d == 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and other, returning -1, 0 or 1
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and other are numerically equal (2.50 equals 2.5)
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// LessThan reports whether d < other
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+// GreaterThan reports whether d > other
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{unscaled: a.Sub(a, b), scale: scale}
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Mul returns the exact product d × other
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), other.int()), scale: d.scale + other.scale}
+}
+
+// MulInt returns d × n
+func (d Decimal) MulInt(n int64) Decimal {
+	return d.Mul(NewFromInt(n))
+}
+
+// Div returns d / other rounded to scale decimal places.
+// It panics on division by zero, like integer division.
+func (d Decimal) Div(other Decimal, scale int, mode RoundingMode) Decimal {
+	if other.IsZero() {
+		panic("decimal: division by zero")
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	// d/other × 10^scale = (d.u × 10^(other.s + scale - d.s)) / other.u
+	num := new(big.Int).Set(d.int())
+	den := new(big.Int).Set(other.int())
+	if shift := other.scale + scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return Decimal{unscaled: roundQuo(num, den, mode), scale: scale}
+}
+
+// DivInt returns d / n rounded to scale decimal places
+func (d Decimal) DivInt(n int64, scale int, mode RoundingMode) Decimal {
+	return d.Div(NewFromInt(n), scale, mode)
+}
+
+// Round returns d rounded to scale decimal places using mode.
+// If d already has no more than scale places it is returned at that scale.
+func (d Decimal) Round(scale int, mode RoundingMode) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if d.scale <= scale {
+		return Decimal{unscaled: d.rescale(scale), scale: scale}
+	}
+	return Decimal{unscaled: roundQuo(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+// roundQuo returns num/den as an integer rounded according to mode
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	negative := num.Sign()*den.Sign() < 0
+	// Compare 2×|rem| with |den| to find which side of half we are on
+	half := new(big.Int).Lsh(new(big.Int).Abs(rem), 1).Cmp(new(big.Int).Abs(den))
+
+	awayFromZero := false
+	switch mode {
+	case RoundDown:
+		awayFromZero = false
+	case RoundUp:
+		awayFromZero = true
+	case RoundFloor:
+		awayFromZero = negative
+	case RoundCeiling:
+		awayFromZero = !negative
+	case RoundHalfUp:
+		awayFromZero = half >= 0
+	case RoundHalfEven:
+		awayFromZero = half > 0 || (half == 0 && quo.Bit(0) == 1)
+	}
+
+	if awayFromZero {
+		if negative {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+// Truncate drops digits beyond scale (same as Round with RoundDown)
+func (d Decimal) Truncate(scale int) Decimal {
+	return d.Round(scale, RoundDown)
+}
+
+// Normalize removes trailing fractional zeros (2.5000 -> 2.5)
+func (d Decimal) Normalize() Decimal {
+	u := new(big.Int).Set(d.int())
+	scale := d.scale
+	rem := new(big.Int)
+	for scale > 0 {
+		q, r := new(big.Int).QuoRem(u, bigTen, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		u = q
+		scale--
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// String returns d with exactly Scale() fractional digits, e.g. "10.50"
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// StringFixed rounds half-up to places decimals and formats with exactly that many
+func (d Decimal) StringFixed(places int) string {
+	return d.Round(places, RoundHalfUp).String()
+}
+
+// StringMTS formats d for MTS amounts: rounded half-up to 8 decimal places
+// with trailing zeros removed ("10.50000000" -> "10.5")
+func (d Decimal) StringMTS() string {
+	return d.Round(MTSScale, RoundHalfUp).Normalize().String()
+}
+
+// Float64 returns the nearest float64; only for display and logging
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IntPart returns the integer part of d truncated toward zero
+func (d Decimal) IntPart() int64 {
+	return d.Truncate(0).int().Int64()
+}
+
+// MarshalJSON encodes d as a JSON string to preserve precision
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both JSON strings ("1.50") and numbers (1.50)
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if strings.HasPrefix(s, "\"") {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Min returns the smaller of a and b
+func Min(a, b Decimal) Decimal {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Max returns the larger of a and b
+func Max(a, b Decimal) Decimal {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// Sum returns the total of values
+func Sum(values ...Decimal) Decimal {
+	total := Zero
+	for _, v := range values {
+		total = total.Add(v)
+	}
+	return total
+}
@@ -0,0 +1,156 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"10", "10"},
+		{"10.50", "10.50"},
+		{"-0.5", "-0.5"},
+		{".25", "0.25"},
+		{"+3.", "3"},
+		{"0.00000001", "0.00000001"},
+		{"123456789012345678901234.12345678", "123456789012345678901234.12345678"},
+	}
+
+	for _, tt := range tests {
+		d, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+		}
+		if d.String() != tt.expected {
+			t.Errorf("Parse(%q).String() = %q, expected %q", tt.input, d.String(), tt.expected)
+		}
+	}
+
+	for _, bad := range []string{"", "-", ".", "1e5", "1,000", " 1", "abc", "1.2.3", "NaN"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) expected error", bad)
+		}
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	a := MustParse("0.1")
+	b := MustParse("0.2")
+	if got := a.Add(b); !got.Equal(MustParse("0.3")) {
+		t.Errorf("0.1 + 0.2 = %s, expected 0.3", got)
+	}
+	if got := MustParse("2.50").Mul(MustParse("1.80")); got.String() != "4.5000" {
+		t.Errorf("2.50 × 1.80 = %s, expected 4.5000", got)
+	}
+	if got := MustParse("1").Sub(MustParse("1.25")); got.String() != "-0.25" {
+		t.Errorf("1 - 1.25 = %s, expected -0.25", got)
+	}
+	if got := MustParse("10").DivInt(3, MTSScale, RoundDown); got.String() != "3.33333333" {
+		t.Errorf("10 / 3 = %s, expected 3.33333333", got)
+	}
+	if got := MustParse("2").Div(MustParse("3"), 2, RoundHalfUp); got.String() != "0.67" {
+		t.Errorf("2 / 3 = %s, expected 0.67", got)
+	}
+	if got := MustParse("-2").Div(MustParse("3"), 2, RoundHalfUp); got.String() != "-0.67" {
+		t.Errorf("-2 / 3 = %s, expected -0.67", got)
+	}
+	if got := MustParse("1.5").Div(MustParse("0.5"), 0, RoundDown); got.String() != "3" {
+		t.Errorf("1.5 / 0.5 = %s, expected 3", got)
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		input    string
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.345", RoundHalfUp, "2.35"},
+		{"-2.345", RoundHalfUp, "-2.35"},
+		{"2.345", RoundHalfEven, "2.34"},
+		{"2.355", RoundHalfEven, "2.36"},
+		{"2.3451", RoundHalfEven, "2.35"},
+		{"2.349", RoundDown, "2.34"},
+		{"-2.349", RoundDown, "-2.34"},
+		{"2.341", RoundUp, "2.35"},
+		{"-2.341", RoundUp, "-2.35"},
+		{"-2.341", RoundFloor, "-2.35"},
+		{"2.349", RoundFloor, "2.34"},
+		{"-2.349", RoundCeiling, "-2.34"},
+		{"2.341", RoundCeiling, "2.35"},
+		{"2.3", RoundHalfUp, "2.30"},
+	}
+
+	for _, tt := range tests {
+		got := MustParse(tt.input).Round(2, tt.mode)
+		if got.String() != tt.expected {
+			t.Errorf("Round(%s, 2, %d) = %s, expected %s", tt.input, tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestMTSFormatting(t *testing.T) {
+	tests := []struct {
+		input string
+		fixed string
+		mts   string
+	}{
+		{"10", "10.00000000", "10"},
+		{"10.50", "10.50000000", "10.5"},
+		{"0.123456789", "0.12345679", "0.12345679"},
+		{"0.000000004", "0.00000000", "0"},
+	}
+
+	for _, tt := range tests {
+		d := MustParse(tt.input)
+		if got := d.StringFixed(MTSScale); got != tt.fixed {
+			t.Errorf("StringFixed(%s) = %s, expected %s", tt.input, got, tt.fixed)
+		}
+		if got := d.StringMTS(); got != tt.mts {
+			t.Errorf("StringMTS(%s) = %s, expected %s", tt.input, got, tt.mts)
+		}
+	}
+}
+
+func TestNewFromFloatAvoidsBinaryNoise(t *testing.T) {
+	if got := NewFromFloat(0.1).String(); got != "0.1" {
+		t.Errorf("NewFromFloat(0.1) = %s, expected 0.1", got)
+	}
+	if got := NewFromFloat(1.23456789).String(); got != "1.23456789" {
+		t.Errorf("NewFromFloat(1.23456789) = %s, expected 1.23456789", got)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1.50","b":2.25}`), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if v.A.String() != "1.50" || v.B.String() != "2.25" {
+		t.Errorf("Unexpected values: a=%s b=%s", v.A, v.B)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"a":"1.50","b":"2.25"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var d Decimal
+	if !d.IsZero() || d.String() != "0" {
+		t.Errorf("Zero value should be 0, got %s", d)
+	}
+	if got := d.Add(MustParse("1.5")); got.String() != "1.5" {
+		t.Errorf("0 + 1.5 = %s", got)
+	}
+}
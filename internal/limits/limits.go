@@ -0,0 +1,279 @@
+// Package limits checks tickets against the operator's own betting limits
+// before they are sent to MTS, so tickets MTS would refuse, or that break
+// brand rules stricter than MTS's, are rejected without a round trip.
+package limits
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+// Violation codes, set as models.BuilderError.Code
+const (
+	CodeMaxStake      = "max_stake"
+	CodeMaxPayout     = "max_payout"
+	CodeMaxSelections = "max_selections"
+	CodeMaxLines      = "max_lines"
+	CodeMinOdds       = "min_odds"
+)
+
+// Limits are the local betting limits. Stake and payout limits are amounts in
+// Currency; stakes in other currencies are converted with the currency
+// registry, so Currency must then be its settlement currency. Without a
+// Currency, amounts are compared as given whatever their currency.
+// Zero values and missing bet kinds are not checked.
+type Limits struct {
+	Currency      string                     `json:"currency,omitempty"`
+	MaxStake      map[string]decimal.Decimal `json:"maxStake,omitempty"`      // Total stake of a bet, by bet kind
+	MaxPayout     *decimal.Decimal           `json:"maxPayout,omitempty"`     // Potential return of a ticket if every line wins
+	MaxSelections map[string]int             `json:"maxSelections,omitempty"` // Selections in a bet, by bet kind
+	MaxLines      int                        `json:"maxLines,omitempty"`      // Lines of a system or banker bet
+	MinOdds       *decimal.Decimal           `json:"minOdds,omitempty"`       // Decimal odds of every selection
+}
+
+// Load reads a limits file, e.g.
+//
+//	{"currency": "EUR", "maxStake": {"single": "1000", "system": "200"}, "maxPayout": "50000",
+//	 "maxSelections": {"accumulator": 20, "system": 12}, "maxLines": 500, "minOdds": "1.10"}
+func Load(path string) (*Limits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read limits file: %w", err)
+	}
+	var l Limits
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%s: invalid limits file: %w", path, err)
+	}
+	if err := l.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &l, nil
+}
+
+func (l *Limits) validate() error {
+	for kind, max := range l.MaxStake {
+		if max.Sign() <= 0 {
+			return fmt.Errorf("maxStake.%s must be greater than 0", kind)
+		}
+	}
+	if l.MaxPayout != nil && l.MaxPayout.Sign() <= 0 {
+		return fmt.Errorf("maxPayout must be greater than 0")
+	}
+	for kind, max := range l.MaxSelections {
+		if max < 1 {
+			return fmt.Errorf("maxSelections.%s must be at least 1", kind)
+		}
+	}
+	if l.MaxLines < 0 {
+		return fmt.Errorf("maxLines must not be negative")
+	}
+	if l.MinOdds != nil && l.MinOdds.LessThan(decimal.One) {
+		return fmt.Errorf("minOdds must be at least 1")
+	}
+	return nil
+}
+
+// Check reports every limit a ticket breaks as models.BuilderErrors naming the
+// offending field, with the violation in Code. Limits apply to each bet of the
+// request: the MTS bets it was placed as (each-way parts, preset parts, one
+// bet per size) are added up, and BetIndex is the request index. A nil
+// *Limits checks nothing.
+func (l *Limits) Check(ticket *models.TicketRequest, currencies *currency.Registry) error {
+	if l == nil {
+		return nil
+	}
+	var errs models.BuilderErrors
+	fail := func(index int, field, code, format string, args ...interface{}) {
+		errs = append(errs, &models.BuilderError{BetIndex: index, Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, req := range groupBets(ticket) {
+		kind := req.kind
+
+		if max, ok := l.MaxStake[kind]; ok {
+			if total, code, err := l.requestStake(req, currencies); err != nil {
+				fail(req.index, "stake", CodeMaxStake, "%v", err)
+			} else if total.GreaterThan(max) {
+				fail(req.index, "stake", CodeMaxStake, "total stake %s %s exceeds the %s limit of %s", total.StringMTS(), code, kind, max.StringMTS())
+			}
+		}
+
+		legs := req.legs()
+		if max, ok := l.MaxSelections[kind]; ok {
+			if count := len(legs); count > max {
+				fail(req.index, "selections", CodeMaxSelections, "%d selections exceed the %s limit of %d", count, kind, max)
+			}
+		}
+
+		if l.MaxLines > 0 && (kind == models.BetKindSystem || kind == models.BetKindBanker) {
+			if lines, err := req.lines(); err == nil && lines > int64(l.MaxLines) {
+				fail(req.index, "size", CodeMaxLines, "%d lines exceed the limit of %d", lines, l.MaxLines)
+			}
+		}
+
+		if l.MinOdds != nil {
+			for _, leg := range legs {
+				if leg.Selection.Odds == nil {
+					continue
+				}
+				value, err := decimal.Parse(leg.Selection.Odds.Value)
+				if err == nil && value.LessThan(*l.MinOdds) {
+					fail(req.index, leg.Field+".odds", CodeMinOdds, "odds %s are below the minimum of %s", value.StringMTS(), l.MinOdds.StringMTS())
+				}
+			}
+		}
+	}
+
+	if l.MaxPayout != nil {
+		if payout, code, err := l.ticketPayout(ticket, currencies); err != nil {
+			fail(-1, "payout", CodeMaxPayout, "%v", err)
+		} else if payout.GreaterThan(*l.MaxPayout) {
+			fail(-1, "payout", CodeMaxPayout, "potential payout %s %s exceeds the limit of %s", payout.StringMTS(), code, l.MaxPayout.StringMTS())
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// requestBet is one bet of the request with the MTS bets it was placed as
+type requestBet struct {
+	index int
+	kind  string // The most combinatorial kind among its bets
+	bets  []models.Bet
+}
+
+// groupBets collects the bets of a ticket by request index, in request order
+func groupBets(ticket *models.TicketRequest) []*requestBet {
+	var reqs []*requestBet
+	byIndex := make(map[int]*requestBet)
+	for _, bet := range ticket.Content.Bets {
+		req, ok := byIndex[bet.Index]
+		if !ok {
+			req = &requestBet{index: bet.Index}
+			byIndex[bet.Index] = req
+			reqs = append(reqs, req)
+		}
+		req.bets = append(req.bets, bet)
+		if kind := bet.Kind(); kindRank(kind) > kindRank(req.kind) {
+			req.kind = kind
+		}
+	}
+	return reqs
+}
+
+func kindRank(kind string) int {
+	switch kind {
+	case models.BetKindSingle:
+		return 1
+	case models.BetKindAccumulator:
+		return 2
+	case models.BetKindSystem:
+		return 3
+	case models.BetKindBanker:
+		return 4
+	}
+	return 0
+}
+
+// legs returns the distinct request selections of the bet. A selection shared
+// by several parts, e.g. the win and place parts of an each-way bet, is
+// listed once, with the odds of its first part.
+func (req *requestBet) legs() []models.Leg {
+	var legs []models.Leg
+	seen := make(map[string]bool)
+	for _, bet := range req.bets {
+		for _, leg := range models.RequestLegs(bet) {
+			if !seen[leg.Field] {
+				seen[leg.Field] = true
+				legs = append(legs, leg)
+			}
+		}
+	}
+	return legs
+}
+
+// lines returns the lines of all the bets together
+func (req *requestBet) lines() (int64, error) {
+	var total int64
+	for _, bet := range req.bets {
+		lines, err := models.CountLines(bet)
+		if err != nil {
+			return 0, err
+		}
+		total += lines
+	}
+	return total, nil
+}
+
+// requestStake returns the total stake of all the bets in the limits currency
+func (l *Limits) requestStake(req *requestBet, currencies *currency.Registry) (decimal.Decimal, string, error) {
+	total, code := decimal.Zero, l.Currency
+	for _, bet := range req.bets {
+		stake, c, err := l.betStake(bet, currencies)
+		if err != nil {
+			return decimal.Decimal{}, "", err
+		}
+		total, code = total.Add(stake), c
+	}
+	return total, code, nil
+}
+
+// betStake returns the total stake of a bet in the limits currency
+func (l *Limits) betStake(bet models.Bet, currencies *currency.Registry) (decimal.Decimal, string, error) {
+	if len(bet.Stake) == 0 {
+		return decimal.Zero, l.Currency, nil
+	}
+	lines, err := models.CountLines(bet)
+	if err != nil {
+		return decimal.Decimal{}, "", err
+	}
+	total := decimal.Zero
+	for k, stake := range bet.Stake {
+		amount, err := decimal.Parse(stake.Amount)
+		if err != nil {
+			return decimal.Decimal{}, "", fmt.Errorf("stake[%d]: invalid amount %q", k, stake.Amount)
+		}
+		total = total.Add(amount)
+	}
+	if bet.Stake[0].Mode == "unit" {
+		total = total.MulInt(lines)
+	}
+	return l.convert(total, bet.Stake[0].Currency, currencies)
+}
+
+// ticketPayout returns the return of a ticket if every line wins, in the
+// limits currency
+func (l *Limits) ticketPayout(ticket *models.TicketRequest, currencies *currency.Registry) (decimal.Decimal, string, error) {
+	quote, err := models.QuoteTicket(ticket)
+	if err != nil {
+		return decimal.Decimal{}, "", err
+	}
+	payout, err := decimal.Parse(quote.MaxReturn)
+	if err != nil {
+		return decimal.Decimal{}, "", err
+	}
+	return l.convert(payout, quote.Currency, currencies)
+}
+
+// convert turns an amount into the limits currency
+func (l *Limits) convert(amount decimal.Decimal, code string, currencies *currency.Registry) (decimal.Decimal, string, error) {
+	if l.Currency == "" || code == l.Currency {
+		return amount, code, nil
+	}
+	if currencies == nil || currencies.Settlement() != l.Currency {
+		return decimal.Decimal{}, "", fmt.Errorf("cannot convert %s into the limits currency %s", code, l.Currency)
+	}
+	converted, err := currencies.ToSettlement(amount, code)
+	if err != nil {
+		return decimal.Decimal{}, "", fmt.Errorf("cannot convert %s into %s: %w", code, l.Currency, err)
+	}
+	return converted, l.Currency, nil
+}
@@ -0,0 +1,134 @@
+package limits
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+func TestCheckReportsEveryViolation(t *testing.T) {
+	payout := decimal.MustParse("100")
+	minOdds := decimal.MustParse("1.20")
+	l := &Limits{
+		MaxStake:      map[string]decimal.Decimal{models.BetKindSingle: decimal.MustParse("50")},
+		MaxPayout:     &payout,
+		MaxSelections: map[string]int{models.BetKindAccumulator: 2},
+		MaxLines:      3,
+		MinOdds:       &minOdds,
+	}
+
+	sels := []models.Selection{
+		models.NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		models.NewSelection("3", "sr:match:2", "1", "1", "1.10"),
+		models.NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+		models.NewSelection("3", "sr:match:4", "1", "1", "2.00"),
+	}
+	ticket, err := models.NewTicketBuilder(45426, "limits-001").
+		AddSingleBet(sels[0], models.NewStake("cash", "EUR", "60", "total")).
+		AddAccumulatorBet(sels[2:], models.NewStake("cash", "EUR", "1", "total")).
+		AddAccumulatorBet([]models.Selection{sels[0], sels[2], sels[3]}, models.NewStake("cash", "EUR", "1", "total")).
+		AddBankerSystemBet(sels[1:2], []int{1, 2}, []models.Selection{sels[0], sels[2], sels[3]}, models.NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-limits")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	err = l.Check(ticket, currency.Default())
+	errs, ok := err.(models.BuilderErrors)
+	if !ok {
+		t.Fatalf("Expected BuilderErrors, got %v", err)
+	}
+	expected := []struct {
+		index       int
+		field, code string
+	}{
+		{0, "stake", CodeMaxStake},
+		{2, "selections", CodeMaxSelections},
+		{3, "size", CodeMaxLines},
+		{3, "bankers[0].odds", CodeMinOdds},
+		{-1, "payout", CodeMaxPayout},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d violations, got %v", len(expected), errs)
+	}
+	for i, want := range expected {
+		if e := errs[i]; e.BetIndex != want.index || e.Field != want.field || e.Code != want.code {
+			t.Errorf("Violation %d = %+v, expected %+v", i, e, want)
+		}
+	}
+
+	var none *Limits
+	if err := none.Check(ticket, nil); err != nil {
+		t.Errorf("Expected nil limits to check nothing, got %v", err)
+	}
+}
+
+func TestCheckConvertsToLimitsCurrency(t *testing.T) {
+	registry, err := currency.Parse([]byte(`{"settlementCurrency": "EUR", "currencies": [
+		{"code": "EUR", "decimals": 2}, {"code": "USD", "decimals": 2, "rate": "0.5"}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse registry: %v", err)
+	}
+	l := &Limits{Currency: "EUR", MaxStake: map[string]decimal.Decimal{models.BetKindSingle: decimal.MustParse("10")}}
+
+	sel := models.NewSelection("3", "sr:match:1", "1", "1", "2.00")
+	for amount, allowed := range map[string]bool{"20": true, "20.02": false} {
+		ticket, _ := models.NewTicketBuilder(45426, "limits-002").
+			AddSingleBet(sel, models.NewStake("cash", "USD", amount, "total")).
+			Build("corr")
+		if err := l.Check(ticket, registry); (err == nil) != allowed {
+			t.Errorf("USD %s: expected allowed=%v, got %v", amount, allowed, err)
+		}
+	}
+}
+
+func TestCheckAddsUpTheBetsOfARequestBet(t *testing.T) {
+	l := &Limits{
+		MaxStake:      map[string]decimal.Decimal{models.BetKindSystem: decimal.MustParse("15")},
+		MaxSelections: map[string]int{models.BetKindSystem: 6},
+		MaxLines:      8,
+	}
+
+	sels := make([]models.Selection, 6)
+	for i := range sels {
+		sels[i] = models.NewSelection("3", fmt.Sprintf("sr:match:%d", i+1), "1", "1", "2.00")
+	}
+	// The alphabet becomes 4 MTS bets and the system one per size, so every
+	// MTS bet on its own is within the limits
+	ticket, err := models.NewTicketBuilder(45426, "limits-003").
+		AddPresetBet("alphabet", sels, models.NewStake("cash", "EUR", "0.10", "unit")).
+		AddSystemBet([]int{2, 3}, sels[:4], models.NewStake("cash", "EUR", "1", "unit")).
+		SetSizeStakes([]models.SizeStake{
+			{Size: 2, Stakes: []models.Stake{models.NewStake("cash", "EUR", "2", "unit")}},
+			{Size: 3, Stakes: []models.Stake{models.NewStake("cash", "EUR", "1", "unit")}},
+		}).
+		Build("corr-limits-003")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	err = l.Check(ticket, currency.Default())
+	errs, ok := err.(models.BuilderErrors)
+	if !ok {
+		t.Fatalf("Expected BuilderErrors, got %v", err)
+	}
+	expected := []struct {
+		index       int
+		field, code string
+	}{
+		{0, "size", CodeMaxLines},  // 26 alphabet lines
+		{1, "stake", CodeMaxStake}, // 6 doubles at 2 + 4 trebles at 1
+		{1, "size", CodeMaxLines},  // 10 lines
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d violations, got %v", len(expected), errs)
+	}
+	for i, want := range expected {
+		if e := errs[i]; e.BetIndex != want.index || e.Field != want.field || e.Code != want.code {
+			t.Errorf("Violation %d = %+v, expected %+v", i, e, want)
+		}
+	}
+}
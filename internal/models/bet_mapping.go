@@ -0,0 +1,82 @@
+package models
+
+import "fmt"
+
+// BetMapping ties a bet of a ticket response to the bet of the request it answers
+type BetMapping struct {
+	BetID    string `json:"betId,omitempty"`
+	Index    int    `json:"index"`          // Index of the bet in the request; both parts of an each-way bet share it
+	Position int    `json:"position"`       // Index of the bet in the ticket sent to MTS
+	Part     string `json:"part,omitempty"` // "win" or "place" for each-way bets
+	Type     string `json:"type"`           // BetKindSingle, BetKindAccumulator, BetKindSystem or BetKindBanker
+	Notation string `json:"notation"`       // Canonical notation of the bet as placed, e.g. "2,3/4"; see BetNotation
+	Status   string `json:"status,omitempty"`
+	Code     int    `json:"code,omitempty"`
+}
+
+// MapBetDetails maps every bet of a ticket response to its request bet, by bet
+// ID or else by position. Response bets that match no request bet are left out.
+func MapBetDetails(ticket *TicketRequest, resp *TicketResponse) []BetMapping {
+	if ticket == nil || resp == nil {
+		return nil
+	}
+	var mappings []BetMapping
+	for i, detail := range resp.Content.BetDetails {
+		position, ok := ticket.BetPosition(detail, i)
+		if !ok {
+			continue
+		}
+		bet := ticket.Content.Bets[position]
+		betID := detail.BetID
+		if betID == "" {
+			betID = bet.BetID
+		}
+		mappings = append(mappings, BetMapping{
+			BetID:    betID,
+			Index:    bet.Index,
+			Position: position,
+			Part:     bet.Part,
+			Type:     bet.Kind(),
+			Notation: BetNotation(bet),
+			Status:   detail.Status,
+			Code:     detail.Code,
+		})
+	}
+	return mappings
+}
+
+// RequestLegs pairs the selections of a built bet with their fields in the bet
+// request: "selection" for a single, "selections[i]" for the selections of a
+// system selection and "bankers[i]" for the selections beside it. The legs of
+// a preset part are numbered as in the preset's selections.
+func RequestLegs(bet Bet) []Leg {
+	switch bet.Kind() {
+	case BetKindSingle:
+		return []Leg{{Field: "selection", Selection: bet.Selections[0]}}
+	case BetKindSystem, BetKindBanker:
+		var legs, bankers []Leg
+		for _, sel := range bet.Selections {
+			if sel.Type == SelectionTypeSystem {
+				legs = append(legs, LabelLegs("selections", sel.Selections)...)
+			} else {
+				bankers = append(bankers, Leg{Field: fmt.Sprintf("bankers[%d]", len(bankers)), Selection: sel})
+			}
+		}
+		if len(bankers) == 0 {
+			return presetLegs(bet, legs)
+		}
+		return append(legs, bankers...)
+	}
+	return presetLegs(bet, LabelLegs("selections", bet.Selections))
+}
+
+// presetLegs renames the legs of a preset part after the request's selections
+func presetLegs(bet Bet, legs []Leg) []Leg {
+	if len(bet.Legs) != len(legs) {
+		return legs
+	}
+	for i := range legs {
+		legs[i].Field = fmt.Sprintf("selections[%d]", bet.Legs[i])
+	}
+	return legs
+}
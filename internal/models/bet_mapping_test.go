@@ -0,0 +1,97 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBetIDsAndResponseMapping(t *testing.T) {
+	horse := NewSelection("3", "sr:stage:1", "1", "1", "6.00")
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+	}
+	ticket, err := NewTicketBuilder(45426, "ids-001").
+		AddSingleBet(horse, NewStake("cash", "EUR", "1", "total")).
+		AddEachWay(EachWayTerms{Fraction: "1/4", Places: 3, PlaceMarketID: "40"}).
+		SetBetID("ew").
+		AddSystemBet([]int{2}, sels, NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-ids")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	var ids []string
+	for _, bet := range ticket.Content.Bets {
+		ids = append(ids, bet.BetID)
+	}
+	if len(ids) != 3 || ids[0] != "ew" || ids[1] != "ew-place" || ids[2] != "ids-001-2" {
+		t.Fatalf("Unexpected bet IDs %v", ids)
+	}
+
+	// MTS may answer in any order; bet IDs take precedence over positions
+	resp := &TicketResponse{Content: TicketResponseContent{
+		Status: "accepted",
+		BetDetails: []BetDetail{
+			{BetID: "ids-001-2", Status: "accepted"},
+			{BetID: "ew-place"},
+			{BetID: "unknown"},
+		},
+	}}
+	mappings := MapBetDetails(ticket, resp)
+	if len(mappings) != 2 {
+		t.Fatalf("Expected 2 mapped bets, got %+v", mappings)
+	}
+	if m := mappings[0]; m.Index != 1 || m.Position != 2 || m.Type != BetKindSystem || m.Status != "accepted" {
+		t.Errorf("Unexpected mapping %+v", m)
+	}
+	if m := mappings[1]; m.Index != 0 || m.Position != 1 || m.Part != BetPartPlace || m.Type != BetKindSingle {
+		t.Errorf("Unexpected mapping %+v", m)
+	}
+}
+
+func TestDuplicateBetIDs(t *testing.T) {
+	sel := NewSelection("3", "sr:match:1", "1", "1", "2.00")
+	_, err := NewTicketBuilder(45426, "ids-002").
+		AddSingleBet(sel, NewStake("cash", "EUR", "1", "total")).SetBetID("a").
+		AddSingleBet(sel, NewStake("cash", "EUR", "1", "total")).SetBetID("a").
+		Build("corr")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 1 || errs[0].BetIndex != 1 || errs[0].Field != "betId" {
+		t.Errorf("Expected a betId error on bet 1, got %v", err)
+	}
+}
+
+func TestDerivedBetIDs(t *testing.T) {
+	horse := NewSelection("3", "sr:stage:1", "1", "1", "6.00")
+	terms := EachWayTerms{Fraction: "1/4", Places: 3, PlaceMarketID: "40"}
+
+	// The place part of "a" is "a-place", which bet 1 also asks for
+	_, err := NewTicketBuilder(45426, "ids-003").
+		AddSingleBet(horse, NewStake("cash", "EUR", "1", "total")).AddEachWay(terms).SetBetID("a").
+		AddSingleBet(horse, NewStake("cash", "EUR", "1", "total")).SetBetID("a-place").
+		Build("corr")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 1 || errs[0].BetIndex != 1 || errs[0].Field != "betId" {
+		t.Errorf("Expected a betId error on bet 1, got %v", err)
+	}
+
+	// Given IDs leave room for the suffixes
+	longest := strings.Repeat("x", maxBetIDLength-betIDSuffixRoom)
+	ticket, err := NewTicketBuilder(45426, "ids-004").
+		AddSingleBet(horse, NewStake("cash", "EUR", "1", "total")).AddEachWay(terms).SetBetID(longest).
+		Build("corr")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	if id := ticket.Content.Bets[1].BetID; id != longest+"-place" {
+		t.Errorf("Unexpected place bet ID %q", id)
+	}
+	_, err = NewTicketBuilder(45426, "ids-005").
+		AddSingleBet(horse, NewStake("cash", "EUR", "1", "total")).SetBetID(longest + "x").
+		Build("corr")
+	if errs, ok := err.(BuilderErrors); !ok || len(errs) != 1 || errs[0].Field != "betId" {
+		t.Errorf("Expected a betId length error, got %v", err)
+	}
+}
@@ -111,7 +111,7 @@ type EndCustomerSuggestions struct {
 // EndCustomer represents end customer information
 type EndCustomer struct {
 	ID         string `json:"id"`
-	Confidence string `json:"confidence"`
+	Confidence string `json:"confidence,omitempty"`
 }
 
 // ChannelSuggestions represents channel suggestions
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// CashoutRules control how a fair value becomes a suggested cashout amount
+type CashoutRules struct {
+	Margin   decimal.Decimal      // Share of the fair value kept by the operator, 0 to below 1
+	Scale    int                  // Decimal places of the suggested amount
+	Rounding decimal.RoundingMode // How the suggested amount is rounded to Scale
+}
+
+// CashoutValuation is the locally calculated cashout value of a placed ticket
+type CashoutValuation struct {
+	TicketID         string             `json:"ticketId"`
+	Currency         string             `json:"currency"`
+	Stake            string             `json:"stake"`
+	FairValue        string             `json:"fairValue"` // Expected return at current probabilities, MTS precision
+	Margin           string             `json:"margin"`
+	SuggestedCashout string             `json:"suggestedCashout"` // Fair value less the margin, rounded by the rules
+	Bets             []BetValuation     `json:"bets"`
+	Comparison       *CashoutComparison `json:"comparison,omitempty"`
+}
+
+// BetValuation is the fair value of one bet of a ticket
+type BetValuation struct {
+	Index     int    `json:"index"`
+	Part      string `json:"part,omitempty"` // "win" or "place" for each-way bets
+	Stake     string `json:"stake"`
+	FairValue string `json:"fairValue"`
+}
+
+// CashoutComparison sets the local valuation against the amounts MTS offered.
+// Differences are local minus MTS, so a positive value means MTS offers less.
+type CashoutComparison struct {
+	MTSFairCashout        string `json:"mtsFairCashout,omitempty"`
+	MTSCashout            string `json:"mtsCashout,omitempty"`
+	FairCashoutDifference string `json:"fairCashoutDifference,omitempty"`
+	CashoutDifference     string `json:"cashoutDifference,omitempty"`
+}
+
+// selectionValue is the expected return per unit staked on one selection and
+// the expected share of the stake it keeps, which scales the free-bet deduction
+type selectionValue struct {
+	expected decimal.Decimal
+	noLoss   decimal.Decimal
+}
+
+// ValueCashout values a placed ticket from the current probability or result
+// of each selection, as returned in cashout-build reply betDetails. Selections
+// are assumed independent, so a line is worth its stake times the product of
+// its selections' expected factors; settled selections use the factors of
+// SettleTicket. Free-bet stake is deducted in proportion to the share of the
+// stake the line is expected to keep.
+func ValueCashout(ticket *TicketRequest, details []CashoutSelectionDetail, rules CashoutRules) (*CashoutValuation, error) {
+	if rules.Margin.Sign() < 0 || !rules.Margin.LessThan(decimal.One) {
+		return nil, fmt.Errorf("margin %s must be at least 0 and below 1", rules.Margin)
+	}
+
+	values := make(map[string]selectionValue, len(details))
+	for i, detail := range details {
+		value, err := valueSelection(detail)
+		if err != nil {
+			return nil, fmt.Errorf("selectionDetails[%d]: %w", i, err)
+		}
+		values[resultKey(detail.Selection)] = value
+	}
+
+	valuation := &CashoutValuation{TicketID: ticket.Content.TicketID, Margin: rules.Margin.String()}
+	totalStake, fair := decimal.Zero, decimal.Zero
+	for i, bet := range ticket.Content.Bets {
+		stake, value, err := valueBet(bet, values)
+		if err != nil {
+			return nil, fmt.Errorf("bet[%d]: %w", i, err)
+		}
+		if currency := bet.Stake[0].Currency; valuation.Currency == "" {
+			valuation.Currency = currency
+		} else if currency != valuation.Currency {
+			return nil, fmt.Errorf("bet[%d]: currency %s differs from %s", i, currency, valuation.Currency)
+		}
+		value = value.Round(decimal.MTSScale, decimal.RoundDown)
+		valuation.Bets = append(valuation.Bets, BetValuation{
+			Index:     i,
+			Part:      bet.Part,
+			Stake:     stake.StringMTS(),
+			FairValue: value.StringMTS(),
+		})
+		totalStake = totalStake.Add(stake)
+		fair = fair.Add(value)
+	}
+
+	valuation.Stake = totalStake.StringMTS()
+	valuation.FairValue = fair.StringMTS()
+	suggested := fair.Mul(decimal.One.Sub(rules.Margin)).Round(rules.Scale, rules.Rounding)
+	valuation.SuggestedCashout = suggested.Normalize().String()
+	return valuation, nil
+}
+
+// CompareWith records the fair and offered amounts of a cashout-build reply
+// next to the local valuation; payouts in other currencies are ignored
+func (v *CashoutValuation) CompareWith(offer *CashoutAmountInfo) error {
+	if offer == nil {
+		return nil
+	}
+	comparison := &CashoutComparison{}
+	local := map[string]string{"fair": v.FairValue, "cashout": v.SuggestedCashout}
+	for _, side := range []struct {
+		name    string
+		payouts []CashoutPayout
+		amount  *string
+		diff    *string
+	}{
+		{"fair", offer.FairCashout, &comparison.MTSFairCashout, &comparison.FairCashoutDifference},
+		{"cashout", offer.Cashout, &comparison.MTSCashout, &comparison.CashoutDifference},
+	} {
+		if len(side.payouts) == 0 {
+			continue
+		}
+		total := decimal.Zero
+		for i, p := range side.payouts {
+			if p.Currency != v.Currency {
+				continue
+			}
+			amount, err := decimal.Parse(p.Amount)
+			if err != nil {
+				return fmt.Errorf("%s[%d]: invalid amount %q", side.name, i, p.Amount)
+			}
+			total = total.Add(amount)
+		}
+		*side.amount = total.StringMTS()
+		*side.diff = decimal.MustParse(local[side.name]).Sub(total).StringMTS()
+	}
+	v.Comparison = comparison
+	return nil
+}
+
+// valueBet returns the total stake and the unrounded fair value of a bet
+func valueBet(bet Bet, values map[string]selectionValue) (totalStake, fair decimal.Decimal, err error) {
+	lines, err := ExpandBet(bet)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	stake, free, err := lineStake(bet.Stake, int64(len(lines)))
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	fair = decimal.Zero
+	for i, line := range lines {
+		expected, noLoss := decimal.One, decimal.One
+		for _, sel := range line.Selections {
+			value, ok := values[resultKey(sel)]
+			if !ok {
+				return decimal.Zero, decimal.Zero, fmt.Errorf("line[%d]: no probability or result for selection %s/%s/%s", i, sel.EventID, sel.MarketID, sel.OutcomeID)
+			}
+			expected = expected.Mul(value.expected)
+			noLoss = noLoss.Mul(value.noLoss)
+		}
+		fair = fair.Add(stake.Mul(expected).Sub(free.Mul(noLoss)))
+	}
+	return stake.MulInt(int64(len(lines))), fair, nil
+}
+
+// valueSelection derives the expected factor of one selection. A settled
+// result is used as is; otherwise the current probabilities weigh a win
+// (odds), refund (1), half-win ((odds + 1) / 2), half-lose (0.5) and the
+// remaining probability of a loss (0).
+func valueSelection(detail CashoutSelectionDetail) (selectionValue, error) {
+	sel := detail.Selection
+	if result := detail.CurrentResult; result != nil && result.Type != ResultUnsettled && result.Type != "" {
+		factor, err := selectionFactor(sel, *result)
+		if err != nil {
+			return selectionValue{}, err
+		}
+		return selectionValue{expected: factor, noLoss: decimal.Min(factor, decimal.One)}, nil
+	}
+
+	prob := detail.CurrentProbability
+	if prob == nil {
+		return selectionValue{}, fmt.Errorf("currentProbability is required for an unsettled selection")
+	}
+	if sel.Odds == nil {
+		return selectionValue{}, fmt.Errorf("odds is required")
+	}
+	odds, err := decimal.Parse(sel.Odds.Value)
+	if err != nil {
+		return selectionValue{}, fmt.Errorf("invalid odds %q", sel.Odds.Value)
+	}
+
+	var p [4]decimal.Decimal
+	total := decimal.Zero
+	for i, field := range []struct{ name, value string }{
+		{"win", prob.Win}, {"refund", prob.Refund}, {"halfWin", prob.HalfWin}, {"halfLose", prob.HalfLose},
+	} {
+		if p[i], err = parseFactor(field.name, field.value, decimal.Zero, true); err != nil {
+			return selectionValue{}, err
+		}
+		total = total.Add(p[i])
+	}
+	if total.GreaterThan(decimal.One) {
+		return selectionValue{}, fmt.Errorf("probabilities add up to %s, more than 1", total)
+	}
+
+	win, refund, halfWin, halfLose := p[0], p[1], p[2], p[3]
+	half := decimal.New(5, 1)
+	expected := win.Mul(odds).
+		Add(refund).
+		Add(halfWin.Mul(odds.Add(decimal.One)).Mul(half)).
+		Add(halfLose.Mul(half))
+	noLoss := win.Add(refund).Add(halfWin).Add(halfLose.Mul(half))
+	return selectionValue{expected: expected, noLoss: noLoss}, nil
+}
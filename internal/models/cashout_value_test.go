@@ -0,0 +1,99 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+func probable(sel Selection, win string) CashoutSelectionDetail {
+	return CashoutSelectionDetail{Selection: sel, CurrentProbability: &CurrentProbability{Type: "normal", Win: win}}
+}
+
+func TestValueCashoutAccumulatorAndTrixie(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "3.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "4.00"),
+	}
+	ticket, err := NewTicketBuilder(45426, "value-001").
+		AddAccumulatorBet(sels[:2], NewStake("cash", "EUR", "10", "total")).
+		AddTrixieBet(sels, NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-value")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	winner := CashoutSelectionDetail{Selection: sels[0], CurrentResult: &CurrentResult{Type: ResultWin}}
+	details := []CashoutSelectionDetail{winner, probable(sels[1], "0.5"), probable(sels[2], "0.25")}
+	rules := CashoutRules{Margin: decimal.MustParse("0.05"), Scale: 2, Rounding: decimal.RoundDown}
+
+	valuation, err := ValueCashout(ticket, details, rules)
+	if err != nil {
+		t.Fatalf("Failed to value ticket: %v", err)
+	}
+	// Accumulator: 10 × 2 × 1.5 = 30
+	// Trixie: 2 × 1.5 + 2 × 1 + 1.5 × 1 + 2 × 1.5 × 1 = 9.5
+	if valuation.Bets[0].FairValue != "30" || valuation.Bets[1].FairValue != "9.5" {
+		t.Errorf("Unexpected bet values %+v", valuation.Bets)
+	}
+	if valuation.Stake != "14" || valuation.FairValue != "39.5" || valuation.SuggestedCashout != "37.52" {
+		t.Errorf("Unexpected valuation %+v", valuation)
+	}
+
+	err = valuation.CompareWith(&CashoutAmountInfo{
+		FairCashout: []CashoutPayout{{Type: "cash", Currency: "EUR", Amount: "38"}},
+		Cashout:     []CashoutPayout{{Type: "cash", Currency: "EUR", Amount: "36.5"}, {Type: "cash", Currency: "USD", Amount: "40"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+	if c := valuation.Comparison; c.FairCashoutDifference != "1.5" || c.MTSCashout != "36.5" || c.CashoutDifference != "1.02" {
+		t.Errorf("Unexpected comparison %+v", c)
+	}
+}
+
+func TestValueCashoutAsianAndFreeStake(t *testing.T) {
+	asian := NewSelection("3", "sr:match:1", "16", "1714", "2.00", "hcp=-0.25")
+	free := NewSelection("3", "sr:match:2", "1", "1", "3.00")
+	ticket, _ := NewTicketBuilder(45426, "value-002").
+		AddSingleBet(asian, NewStake("cash", "EUR", "10", "total")).
+		AddSingleBet(free, NewStake("cash", "EUR", "6", "total"), NewStake("free", "EUR", "4", "total")).
+		Build("corr-value")
+
+	details := []CashoutSelectionDetail{
+		{Selection: asian, CurrentProbability: &CurrentProbability{Type: "push", Win: "0.4", HalfLose: "0.2"}},
+		probable(free, "0.5"),
+	}
+	valuation, err := ValueCashout(ticket, details, CashoutRules{Scale: 2, Rounding: decimal.RoundHalfUp})
+	if err != nil {
+		t.Fatalf("Failed to value ticket: %v", err)
+	}
+	// 10 × (0.4 × 2 + 0.2 × 0.5) = 9
+	// 10 × 0.5 × 3 less 4 free × 0.5 = 13
+	if valuation.Bets[0].FairValue != "9" || valuation.Bets[1].FairValue != "13" || valuation.SuggestedCashout != "22" {
+		t.Errorf("Unexpected valuation %+v", valuation)
+	}
+}
+
+func TestValueCashoutRejectsInvalidInput(t *testing.T) {
+	sel := NewSelection("3", "sr:match:1", "1", "1", "2.00")
+	ticket, _ := NewTicketBuilder(45426, "value-003").AddSingleBet(sel, NewStake("cash", "EUR", "1", "total")).Build("corr")
+	rules := CashoutRules{Scale: 2, Rounding: decimal.RoundDown}
+
+	for name, details := range map[string][]CashoutSelectionDetail{
+		"missing selection":   nil,
+		"no probability":      {{Selection: sel}},
+		"probability above 1": {{Selection: sel, CurrentProbability: &CurrentProbability{Win: "0.7", Refund: "0.4"}}},
+		"bad result":          {{Selection: sel, CurrentResult: &CurrentResult{Type: "push"}}},
+	} {
+		if _, err := ValueCashout(ticket, details, rules); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+
+	rules.Margin = decimal.One
+	if _, err := ValueCashout(ticket, []CashoutSelectionDetail{probable(sel, "0.5")}, rules); err == nil {
+		t.Error("Expected error for a margin of 1")
+	}
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// MaxExpandedLines caps how many lines ExpandBet will enumerate for one bet
+const MaxExpandedLines = 10000
+
+// Line is one combination a bet is settled as
+type Line struct {
+	Index      int         `json:"index"`
+	Selections []Selection `json:"selections"` // Standard selections only, in bet order
+	Odds       string      `json:"odds"`       // Combined decimal odds
+	Stake      string      `json:"stake"`
+	Return     string      `json:"potentialReturn"`
+}
+
+// BetLines lists the lines of one bet of a ticket
+type BetLines struct {
+	BetIndex int    `json:"betIndex"`
+	Part     string `json:"part,omitempty"` // "win" or "place" for each-way bets
+	Lines    []Line `json:"lines"`
+}
+
+// ExpandTicket enumerates the lines of every bet of a ticket
+func ExpandTicket(ticket *TicketRequest) ([]BetLines, error) {
+	result := make([]BetLines, 0, len(ticket.Content.Bets))
+	for i, bet := range ticket.Content.Bets {
+		lines, err := ExpandBet(bet)
+		if err != nil {
+			return nil, fmt.Errorf("bet[%d]: %w", i, err)
+		}
+		result = append(result, BetLines{BetIndex: i, Part: bet.Part, Lines: lines})
+	}
+	return result, nil
+}
+
+// CountLines returns the number of lines of a bet without enumerating them
+func CountLines(bet Bet) (int64, error) {
+	total := int64(1)
+	for j, sel := range bet.Selections {
+		summary, err := summariseSelection(sel)
+		if err != nil {
+			return 0, fmt.Errorf("selection[%d]: %w", j, err)
+		}
+		total *= summary.lines
+	}
+	return total, nil
+}
+
+// ExpandBet enumerates every line of a bet with its combined odds, stake and
+// potential return. Lines are the cross product of the bet's top-level
+// selections, so for the AddBankerSystemBet layout [system, bankers...] each
+// system combination is followed by every banker. Within a system selection
+// lines are ordered by size, then by combination in selection order.
+func ExpandBet(bet Bet) ([]Line, error) {
+	if len(bet.Selections) == 0 || len(bet.Stake) == 0 {
+		return nil, fmt.Errorf("bet needs selections and a stake")
+	}
+
+	// Count first so oversized systems are rejected before enumerating
+	total := int64(1)
+	for j, sel := range bet.Selections {
+		summary, err := summariseSelection(sel)
+		if err != nil {
+			return nil, fmt.Errorf("selection[%d]: %w", j, err)
+		}
+		total *= summary.lines
+		if total > MaxExpandedLines {
+			return nil, fmt.Errorf("bet expands to more than %d lines", MaxExpandedLines)
+		}
+	}
+
+	combos := [][]Selection{{}}
+	for _, sel := range bet.Selections {
+		combos = crossProduct(combos, expandSelection(sel))
+	}
+
+	stake, free, err := lineStake(bet.Stake, int64(len(combos)))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, len(combos))
+	for i, combo := range combos {
+		odds := decimal.One
+		for _, sel := range combo {
+			// Odds were validated by summariseSelection
+			value, _ := decimal.Parse(sel.Odds.Value)
+			odds = odds.Mul(value)
+		}
+		lines[i] = Line{
+			Index:      i,
+			Selections: combo,
+			Odds:       odds.Normalize().String(),
+			Stake:      stake.StringMTS(),
+			Return:     stake.Mul(odds).Sub(free).Round(decimal.MTSScale, decimal.RoundDown).StringMTS(),
+		}
+	}
+	return lines, nil
+}
+
+// lineStake returns the stake each line carries, and how much of it is
+// free-bet stake that is not returned: unit amounts as-is, total amounts
+// split evenly and rounded down to MTS precision
+func lineStake(stakes []Stake, lineCount int64) (perLine, free decimal.Decimal, err error) {
+	perLine, free = decimal.Zero, decimal.Zero
+	for k, stake := range stakes {
+		amount, parseErr := decimal.Parse(stake.Amount)
+		if parseErr != nil {
+			return decimal.Zero, decimal.Zero, fmt.Errorf("stake[%d]: %w", k, parseErr)
+		}
+		if stake.Mode != "unit" {
+			amount = amount.DivInt(lineCount, decimal.MTSScale, decimal.RoundDown)
+		}
+		perLine = perLine.Add(amount)
+		if !ReturnsStake(stake.Type) {
+			free = free.Add(amount)
+		}
+	}
+	return perLine, free, nil
+}
+
+// expandSelection returns every set of standard selections a selection can
+// contribute to a line
+func expandSelection(sel Selection) [][]Selection {
+	if sel.Type != "system" {
+		return [][]Selection{{sel}}
+	}
+
+	children := make([][][]Selection, len(sel.Selections))
+	for i, child := range sel.Selections {
+		children[i] = expandSelection(child)
+	}
+
+	var result [][]Selection
+	for _, size := range sel.Size {
+		forEachCombination(len(children), size, func(indexes []int) {
+			combos := [][]Selection{{}}
+			for _, idx := range indexes {
+				combos = crossProduct(combos, children[idx])
+			}
+			result = append(result, combos...)
+		})
+	}
+	return result
+}
+
+// crossProduct appends every option to every prefix
+func crossProduct(prefixes, options [][]Selection) [][]Selection {
+	result := make([][]Selection, 0, len(prefixes)*len(options))
+	for _, prefix := range prefixes {
+		for _, option := range options {
+			line := make([]Selection, 0, len(prefix)+len(option))
+			line = append(line, prefix...)
+			line = append(line, option...)
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// forEachCombination calls fn with every k-combination of 0..n-1 in lexicographic order
+func forEachCombination(n, k int, fn func([]int)) {
+	if k < 1 || k > n {
+		return
+	}
+	indexes := make([]int, k)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	for {
+		fn(indexes)
+		i := k - 1
+		for i >= 0 && indexes[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		indexes[i]++
+		for j := i + 1; j < k; j++ {
+			indexes[j] = indexes[j-1] + 1
+		}
+	}
+}
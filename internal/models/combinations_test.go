@@ -0,0 +1,120 @@
+package models
+
+import "testing"
+
+func TestExpandTrixie(t *testing.T) {
+	bet := Bet{
+		Selections: []Selection{{
+			Type: "system",
+			Size: []int{2, 3},
+			Selections: []Selection{
+				NewSelection("3", "sr:match:1", "1", "1", "2"),
+				NewSelection("3", "sr:match:2", "1", "1", "3"),
+				NewSelection("3", "sr:match:3", "1", "1", "4"),
+			},
+		}},
+		Stake: []Stake{NewStake("cash", "EUR", "1", "unit")},
+	}
+
+	lines, err := ExpandBet(bet)
+	if err != nil {
+		t.Fatalf("Failed to expand: %v", err)
+	}
+
+	expected := []string{"6", "8", "12", "24"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i, odds := range expected {
+		if lines[i].Odds != odds || lines[i].Return != odds || lines[i].Stake != "1" {
+			t.Errorf("Line %d: expected odds/return %s at stake 1, got %+v", i, odds, lines[i])
+		}
+	}
+	if lines[0].Selections[1].EventID != "sr:match:2" || len(lines[3].Selections) != 3 {
+		t.Errorf("Unexpected line composition: %+v", lines)
+	}
+}
+
+func TestExpandBankerLayout(t *testing.T) {
+	builder := NewTicketBuilder(45426, "lines-001")
+	builder.AddBankerSystemBet(
+		[]Selection{NewSelection("3", "sr:match:9", "1", "1", "1.5")},
+		[]int{2},
+		[]Selection{
+			NewSelection("3", "sr:match:1", "1", "1", "2"),
+			NewSelection("3", "sr:match:2", "1", "1", "2.5"),
+			NewSelection("3", "sr:match:3", "1", "1", "3"),
+		},
+		NewStake("cash", "EUR", "10", "total"),
+	)
+	ticket, err := builder.Build("corr-lines")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	bets, err := ExpandTicket(ticket)
+	if err != nil {
+		t.Fatalf("Failed to expand: %v", err)
+	}
+	lines := bets[0].Lines
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if len(line.Selections) != 3 || line.Selections[2].EventID != "sr:match:9" {
+			t.Errorf("Line %d should end with the banker: %+v", i, line.Selections)
+		}
+		if line.Stake != "3.33333333" {
+			t.Errorf("Line %d: expected stake 3.33333333, got %s", i, line.Stake)
+		}
+	}
+	// 2 × 2.5 × 1.5 at 3.33333333
+	if lines[0].Odds != "7.5" || lines[0].Return != "24.99999997" {
+		t.Errorf("Unexpected first line: odds %s return %s", lines[0].Odds, lines[0].Return)
+	}
+}
+
+func TestExpandRejectsOversizedSystem(t *testing.T) {
+	var selections []Selection
+	for i := 0; i < 20; i++ {
+		selections = append(selections, NewSelection("3", "sr:match:1", "1", "1", "2"))
+	}
+	bet := Bet{
+		Selections: []Selection{{Type: "system", Size: []int{10}, Selections: selections}},
+		Stake:      []Stake{NewStake("cash", "EUR", "1", "unit")},
+	}
+	if _, err := ExpandBet(bet); err == nil {
+		t.Error("Expected error for a bet with 184756 lines")
+	}
+}
+
+func TestBetKindAndLineCount(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2"),
+		NewSelection("3", "sr:match:2", "1", "1", "2"),
+		NewSelection("3", "sr:match:3", "1", "1", "2"),
+	}
+	banker := NewSelection("3", "sr:match:4", "1", "1", "2")
+	stake := NewStake("cash", "EUR", "1", "unit")
+	ticket, err := NewTicketBuilder(45426, "kinds").
+		AddSingleBet(sels[0], stake).
+		AddAccumulatorBet(sels, stake).
+		AddSystemBet([]int{2}, sels, stake).
+		AddBankerSystemBet([]Selection{banker}, []int{1, 2}, sels, stake).
+		Build("corr")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	expected := []struct {
+		kind  string
+		lines int64
+	}{{BetKindSingle, 1}, {BetKindAccumulator, 1}, {BetKindSystem, 3}, {BetKindBanker, 6}}
+	for i, want := range expected {
+		bet := ticket.Content.Bets[i]
+		lines, err := CountLines(bet)
+		if bet.Kind() != want.kind || lines != want.lines || err != nil {
+			t.Errorf("Bet %d: got %s with %d lines (%v), expected %s with %d", i, bet.Kind(), lines, err, want.kind, want.lines)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gdsZyy/mts-service/internal/uof"
+)
+
+// Correlation conflict codes, reported on BuilderError.Code
+const (
+	ConflictDuplicateOutcome  = "duplicate_outcome"  // The same outcome appears twice
+	ConflictMutuallyExclusive = "mutually_exclusive" // Different outcomes of one market and specifier set
+	ConflictSameEvent         = "same_event"         // Legs on one event outside a uf-custom-bet
+)
+
+// Leg is a selection of a bet with the field path it is reported under
+type Leg struct {
+	Field     string
+	Selection Selection
+}
+
+// LabelLegs pairs each selection with its path, e.g. "selections[2]"
+func LabelLegs(field string, selections []Selection) []Leg {
+	legs := make([]Leg, len(selections))
+	for i, sel := range selections {
+		legs[i] = Leg{Field: fmt.Sprintf("%s[%d]", field, i), Selection: sel}
+	}
+	return legs
+}
+
+// Conflict reports a leg that is correlated with an earlier leg of the same bet
+type Conflict struct {
+	Field   string `json:"field"` // The conflicting leg
+	With    string `json:"with"`  // The earlier leg it conflicts with
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// outcomeKey identifies one outcome of one market of an event
+type outcomeKey struct {
+	market, specifiers, outcome string
+}
+
+// FindConflicts checks the legs of one bet for selections that cannot or
+// should not be combined: the same outcome twice, two outcomes of one market
+// and specifier set, and, when combined is true, any two legs on the same
+// event (those must be sent as one uf-custom-bet). Legs of a custom bet are
+// checked against each other for duplicates and exclusive outcomes only.
+// Each leg is reported at most once, against the earliest leg it conflicts
+// with, with the most specific code.
+func FindConflicts(legs []Leg, combined bool) []Conflict {
+	var conflicts []Conflict
+	for i, leg := range legs {
+		conflicts = append(conflicts, customBetConflicts(leg)...)
+
+		var found *Conflict
+		for _, earlier := range legs[:i] {
+			code := legRelation(earlier.Selection, leg.Selection)
+			if code == "" || (code == ConflictSameEvent && !combined) {
+				continue
+			}
+			if found == nil || conflictRank(code) > conflictRank(found.Code) {
+				found = &Conflict{Field: leg.Field, With: earlier.Field, Code: code}
+			}
+		}
+		if found != nil {
+			found.Message = conflictMessage(found.Code, found.With)
+			conflicts = append(conflicts, *found)
+		}
+	}
+	return conflicts
+}
+
+// customBetConflicts checks the legs within a custom bet selection
+func customBetConflicts(leg Leg) []Conflict {
+	if leg.Selection.Type != SelectionTypeCustomBet {
+		return nil
+	}
+	var conflicts []Conflict
+	inner := LabelLegs(leg.Field+".selections", leg.Selection.Selections)
+	for i, a := range inner {
+		for _, b := range inner[:i] {
+			if code := outcomeRelation(keyOf(b.Selection), keyOf(a.Selection)); code != "" {
+				conflicts = append(conflicts, Conflict{Field: a.Field, With: b.Field, Code: code, Message: conflictMessage(code, b.Field)})
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// legRelation returns the conflict code between two top-level legs, or ""
+func legRelation(a, b Selection) string {
+	if a.EventID == "" || eventKey(a) != eventKey(b) {
+		return ""
+	}
+	code := ConflictSameEvent
+	for _, ka := range outcomeKeys(a) {
+		for _, kb := range outcomeKeys(b) {
+			if rel := outcomeRelation(ka, kb); conflictRank(rel) > conflictRank(code) {
+				code = rel
+			}
+		}
+	}
+	return code
+}
+
+// outcomeRelation compares two outcomes of the same event
+func outcomeRelation(a, b outcomeKey) string {
+	if a.market != b.market || a.specifiers != b.specifiers {
+		return ""
+	}
+	if a.outcome == b.outcome {
+		return ConflictDuplicateOutcome
+	}
+	return ConflictMutuallyExclusive
+}
+
+// eventKey namespaces the event ID, since external IDs are operator-defined
+func eventKey(sel Selection) string {
+	if sel.Type == SelectionTypeExternal {
+		return "external:" + sel.EventID
+	}
+	return uof.CanonicalURN(sel.EventID)
+}
+
+// outcomeKeys returns the outcomes a leg backs; a custom bet backs all of its legs
+func outcomeKeys(sel Selection) []outcomeKey {
+	if sel.Type != SelectionTypeCustomBet {
+		return []outcomeKey{keyOf(sel)}
+	}
+	keys := make([]outcomeKey, len(sel.Selections))
+	for i, leg := range sel.Selections {
+		keys[i] = keyOf(leg)
+	}
+	return keys
+}
+
+func keyOf(sel Selection) outcomeKey {
+	if sel.Type == SelectionTypeExternal {
+		return outcomeKey{market: sel.MarketID, specifiers: sel.Specifiers, outcome: sel.OutcomeID}
+	}
+	return outcomeKey{market: sel.MarketID, specifiers: uof.CanonicalSpecifiers(sel.Specifiers), outcome: uof.CanonicalOutcomeID(sel.OutcomeID)}
+}
+
+func conflictRank(code string) int {
+	switch code {
+	case ConflictDuplicateOutcome:
+		return 3
+	case ConflictMutuallyExclusive:
+		return 2
+	case ConflictSameEvent:
+		return 1
+	}
+	return 0
+}
+
+func conflictMessage(code, with string) string {
+	switch code {
+	case ConflictDuplicateOutcome:
+		return fmt.Sprintf("duplicate of %s", with)
+	case ConflictMutuallyExclusive:
+		return fmt.Sprintf("mutually exclusive with %s (same market and specifiers)", with)
+	}
+	return fmt.Sprintf("same event as %s; send same-event legs as one %s selection", with, SelectionTypeCustomBet)
+}
@@ -0,0 +1,108 @@
+package models
+
+import "testing"
+
+func TestFindConflicts(t *testing.T) {
+	legs := LabelLegs("selections", []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.10"),
+		NewSelection("3", "sr:match:2", "18", "12", "1.90", "total=2.5"),
+		NewSelection("3", "sr:match:1", "1", "1", "2.10"),                // duplicate of [0]
+		NewSelection("3", "sr:match:2", "18", "13", "1.95", "total=2.5"), // same market as [1]
+		NewSelection("3", "sr:match:2", "18", "12", "1.40", "total=1.5"), // same event as [1]
+		NewSelection("3", "sr:match:3", "1", "1", "3.00"),                // unrelated
+		NewExternalSelection("sr:match:3", "1", "2", "2.00"),             // external IDs never match UOF ones
+		NewCustomBetSelection("3", "sr:match:1", "5.00", NewCustomBetLeg("1", "2"), NewCustomBetLeg("1", "2")),
+	})
+
+	conflicts := FindConflicts(legs, true)
+	expected := map[string]Conflict{
+		"selections[2]":               {With: "selections[0]", Code: ConflictDuplicateOutcome},
+		"selections[3]":               {With: "selections[1]", Code: ConflictMutuallyExclusive},
+		"selections[4]":               {With: "selections[1]", Code: ConflictSameEvent},
+		"selections[7].selections[1]": {With: "selections[7].selections[0]", Code: ConflictDuplicateOutcome},
+		"selections[7]":               {With: "selections[0]", Code: ConflictMutuallyExclusive},
+	}
+	if len(conflicts) != len(expected) {
+		t.Errorf("Expected %d conflicts, got %+v", len(expected), conflicts)
+	}
+	for _, c := range conflicts {
+		want, ok := expected[c.Field]
+		if !ok {
+			t.Errorf("Unexpected conflict %+v", c)
+			continue
+		}
+		if c.With != want.With || c.Code != want.Code || c.Message == "" {
+			t.Errorf("Conflict at %s = %+v, expected %+v", c.Field, c, want)
+		}
+	}
+
+	// Singles never combine legs, so only exact duplicates are reported
+	if got := FindConflicts(legs[:5], false); len(got) != 2 || got[0].Code != ConflictDuplicateOutcome || got[1].Code != ConflictMutuallyExclusive {
+		t.Errorf("Expected duplicate and exclusive conflicts only, got %+v", got)
+	}
+}
+
+func TestBuilderRejectsCorrelatedLegs(t *testing.T) {
+	_, err := NewTicketBuilder(45426, "test-corr-001").
+		AddAccumulatorBet([]Selection{
+			NewSelection("3", "sr:match:1", "1", "1", "2.10"),
+			NewSelection("3", "sr:match:1", "18", "12", "1.90", "total=2.5"),
+		}, NewStake("cash", "EUR", "5", "total")).
+		AddBankerSystemBet(
+			[]Selection{NewSelection("3", "sr:match:2", "1", "1", "1.50")},
+			[]int{2},
+			[]Selection{
+				NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+				NewSelection("3", "sr:match:4", "1", "1", "2.00"),
+				NewSelection("3", "sr:match:2", "1", "1", "1.50"),
+			},
+			NewStake("cash", "EUR", "1", "unit"),
+		).
+		Build("corr-corr-001")
+	if err == nil {
+		t.Fatal("Expected build error")
+	}
+
+	errs := err.(BuilderErrors)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", err)
+	}
+	if errs[0].BetIndex != 0 || errs[0].Field != "selections[1]" || errs[0].Code != ConflictSameEvent {
+		t.Errorf("Unexpected accumulator error %+v", errs[0])
+	}
+	if errs[1].BetIndex != 1 || errs[1].Field != "selections[2]" || errs[1].Code != ConflictDuplicateOutcome {
+		t.Errorf("Unexpected banker error %+v", errs[1])
+	}
+}
+
+func TestEquivalentSelectionsConflict(t *testing.T) {
+	legs := LabelLegs("selections", []Selection{
+		NewSelection("3", "sr:match:1", "18", "12", "1.90", "total=2.5|hcp=0:1"),
+		NewSelection("3", "SR:Match:1", "18", "012", "1.90", "hcp=0:1|total=2.5"),
+	})
+	conflicts := FindConflicts(legs, false)
+	if len(conflicts) != 1 || conflicts[0].Code != ConflictDuplicateOutcome {
+		t.Errorf("Expected reordered specifiers to be a duplicate, got %+v", conflicts)
+	}
+}
+
+func TestBuilderRejectsMalformedUOFIDs(t *testing.T) {
+	_, err := NewTicketBuilder(45426, "test-uof-001").
+		AddSingleBet(NewSelection("3", "match-1", "1", "1", "2.10"), NewStake("cash", "EUR", "5", "total")).
+		AddSingleBet(NewSelection("3", "sr:match:2", "18", "over", "1.90", "total=2.5|total=3.5"), NewStake("cash", "EUR", "5", "total")).
+		AddSingleBet(NewSelection("3", "sr:match:3", "1", "pre:outcometext:9919", "2.10"), NewStake("cash", "EUR", "5", "total")).
+		Build("corr-uof-001")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", err)
+	}
+	expected := []struct {
+		index int
+		field string
+	}{{0, "selection.eventId"}, {1, "selection.specifiers"}, {1, "selection.outcomeId"}}
+	for i, want := range expected {
+		if errs[i].BetIndex != want.index || errs[i].Field != want.field {
+			t.Errorf("Error %d: expected %+v, got %+v", i, want, errs[i])
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/uof"
+)
+
+// PlaceOddsScale is the number of decimals place-part odds are rounded down to
+const PlaceOddsScale = 2
+
+// Bet parts, recorded on Bet.Part for each-way bets
+const (
+	BetPartWin   = "win"
+	BetPartPlace = "place"
+)
+
+// EachWayTerms are the place terms of an each-way bet
+type EachWayTerms struct {
+	Fraction      string `json:"fraction"`      // Share of the win odds paid for a place, "1/4" or "0.25"
+	Places        int    `json:"places"`        // Number of places paid
+	PlaceMarketID string `json:"placeMarketId"` // Place market the place part is sent to
+}
+
+// ParseFraction returns the place fraction as an exact ratio numerator/denominator
+func (t EachWayTerms) ParseFraction() (num, den decimal.Decimal, err error) {
+	if parts := strings.SplitN(t.Fraction, "/", 2); len(parts) == 2 {
+		num, err = decimal.Parse(parts[0])
+		if err == nil {
+			den, err = decimal.Parse(parts[1])
+		}
+	} else {
+		num, err = decimal.Parse(t.Fraction)
+		den = decimal.One
+	}
+	if err != nil {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("invalid fraction %q", t.Fraction)
+	}
+	if num.Sign() <= 0 || den.Sign() <= 0 || num.GreaterThan(den) {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("fraction %q must be greater than 0 and at most 1", t.Fraction)
+	}
+	return num, den, nil
+}
+
+// Validate checks the place terms
+func (t EachWayTerms) Validate() error {
+	if t.Fraction == "" {
+		return fmt.Errorf("fraction is required")
+	}
+	if _, _, err := t.ParseFraction(); err != nil {
+		return err
+	}
+	if t.Places < 1 {
+		return fmt.Errorf("places must be at least 1")
+	}
+	if t.PlaceMarketID == "" {
+		return fmt.Errorf("placeMarketId is required")
+	}
+	return nil
+}
+
+// PlaceOdds converts win odds to place odds: 1 + (odds - 1) × fraction,
+// rounded down to PlaceOddsScale
+func (t EachWayTerms) PlaceOdds(winOdds string) (string, error) {
+	num, den, err := t.ParseFraction()
+	if err != nil {
+		return "", err
+	}
+	odds, err := decimal.Parse(winOdds)
+	if err != nil {
+		return "", err
+	}
+	profit := odds.Sub(decimal.One).Mul(num).Div(den, PlaceOddsScale, decimal.RoundDown)
+	place := decimal.One.Add(profit)
+	if place.Cmp(decimal.One) <= 0 {
+		return "", fmt.Errorf("odds %s are too short for an each-way place part", winOdds)
+	}
+	return place.String(), nil
+}
+
+// PlaceBet derives the place part of an each-way bet from its win part.
+// Every selection keeps its event and outcome but moves to the place market,
+// with a "places" specifier, and is priced at place odds.
+func (t EachWayTerms) PlaceBet(win Bet) (Bet, error) {
+	selections := make([]Selection, len(win.Selections))
+	for i, sel := range win.Selections {
+		place, err := t.placeSelection(sel)
+		if err != nil {
+			return Bet{}, fmt.Errorf("selections[%d]: %w", i, err)
+		}
+		selections[i] = place
+	}
+	return Bet{
+		Selections: selections,
+		Stake:      append([]Stake{}, win.Stake...),
+		Part:       BetPartPlace,
+	}, nil
+}
+
+func (t EachWayTerms) placeSelection(sel Selection) (Selection, error) {
+	if sel.Type == "system" {
+		nested := make([]Selection, len(sel.Selections))
+		for i, child := range sel.Selections {
+			place, err := t.placeSelection(child)
+			if err != nil {
+				return Selection{}, fmt.Errorf("selections[%d]: %w", i, err)
+			}
+			nested[i] = place
+		}
+		sel.Selections = nested
+		sel.Size = append([]int{}, sel.Size...)
+		return sel, nil
+	}
+	if sel.Type == SelectionTypeCustomBet {
+		return Selection{}, fmt.Errorf("custom bets cannot be placed each-way")
+	}
+
+	if sel.Odds == nil {
+		return Selection{}, fmt.Errorf("odds is required")
+	}
+	odds, err := t.PlaceOdds(sel.Odds.Value)
+	if err != nil {
+		return Selection{}, err
+	}
+	if t.PlaceMarketID == "" {
+		return Selection{}, fmt.Errorf("placeMarketId is required")
+	}
+	specifiers, err := uof.ParseSpecifiers(sel.Specifiers)
+	if err != nil {
+		return Selection{}, err
+	}
+	if _, ok := specifiers.Get("places"); ok {
+		return Selection{}, fmt.Errorf("specifiers %q already set places", sel.Specifiers)
+	}
+	places := fmt.Sprintf("places=%d", t.Places)
+	if len(specifiers) > 0 {
+		places = specifiers.String() + "|" + places
+	}
+	sel.Odds = &Odds{Type: sel.Odds.Type, Value: odds}
+	sel.MarketID = t.PlaceMarketID
+	sel.Specifiers = uof.CanonicalSpecifiers(places)
+	return sel, nil
+}
+
+// AddEachWay turns the bet added by the previous Add call into the win part
+// of an each-way bet and adds its place part with the same stake, so the
+// total staked doubles. Errors are reported against the previous bet.
+func (tb *TicketBuilder) AddEachWay(terms EachWayTerms) *TicketBuilder {
+	index := tb.betCount - 1
+	if index < 0 {
+		tb.fail(-1, "eachWay", "each-way terms must follow a bet")
+		return tb
+	}
+	if err := terms.Validate(); err != nil {
+		tb.fail(index, "eachWay", err.Error())
+		return tb
+	}
+	if tb.lastBet < 0 {
+		// The win part was rejected; its errors are already recorded
+		return tb
+	}
+
+	// A preset made of several bets gets a place part for each of them
+	wins := len(tb.bets)
+	for i := tb.lastBet; i < wins; i++ {
+		win := &tb.bets[i]
+		place, err := terms.PlaceBet(*win)
+		if err != nil {
+			tb.fail(index, "eachWay", err.Error())
+			return tb
+		}
+		win.Part = BetPartWin
+		place.Index = win.Index
+		place.Legs = win.Legs
+		tb.bets = append(tb.bets, place)
+	}
+	tb.lastBet = -1
+	return tb
+}
@@ -0,0 +1,99 @@
+package models
+
+import "testing"
+
+func TestEachWaySingle(t *testing.T) {
+	terms := EachWayTerms{Fraction: "1/4", Places: 3, PlaceMarketID: "40"}
+	builder := NewTicketBuilder(45426, "ew-001")
+	builder.AddSingleBet(NewSelection("3", "sr:stage:1", "1", "7", "9.00"), NewStake("cash", "GBP", "5", "total")).AddEachWay(terms)
+
+	ticket, err := builder.Build("corr-ew")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	if len(ticket.Content.Bets) != 2 {
+		t.Fatalf("Expected win and place bets, got %d", len(ticket.Content.Bets))
+	}
+	win, place := ticket.Content.Bets[0], ticket.Content.Bets[1]
+	if win.Part != BetPartWin || place.Part != BetPartPlace {
+		t.Errorf("Unexpected parts: %q, %q", win.Part, place.Part)
+	}
+	if win.Selections[0].Odds.Value != "9.00" || place.Selections[0].Odds.Value != "3.00" {
+		t.Errorf("Expected odds 9.00/3.00, got %s/%s", win.Selections[0].Odds.Value, place.Selections[0].Odds.Value)
+	}
+	if win.Selections[0].MarketID != "1" || place.Selections[0].MarketID != "40" || place.Selections[0].Specifiers != "places=3" {
+		t.Errorf("Expected the place part on market 40 with places=3, got %+v", place.Selections[0])
+	}
+
+	quote, err := QuoteTicket(ticket)
+	if err != nil {
+		t.Fatalf("Failed to quote: %v", err)
+	}
+	if quote.TotalStake != "10" || quote.MaxReturn != "60" {
+		t.Errorf("Expected stake 10 and max return 60, got %s and %s", quote.TotalStake, quote.MaxReturn)
+	}
+}
+
+func TestEachWayPlaceMarketAndSystem(t *testing.T) {
+	terms := EachWayTerms{Fraction: "0.2", Places: 4, PlaceMarketID: "40"}
+	builder := NewTicketBuilder(45426, "ew-002")
+	builder.AddTrixieBet([]Selection{
+		NewSelection("3", "sr:stage:1", "1", "1", "6"),
+		NewSelection("3", "sr:stage:2", "1", "2", "3.5"),
+		NewSelection("3", "sr:stage:3", "1", "3", "2"),
+	}, NewStake("cash", "GBP", "1", "unit")).AddEachWay(terms)
+
+	ticket, err := builder.Build("corr-ew")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	nested := ticket.Content.Bets[1].Selections[0].Selections
+	if nested[0].MarketID != "40" || nested[0].Specifiers != "places=4" || nested[0].Odds.Value != "2.00" {
+		t.Errorf("Unexpected place selection: %+v", nested[0])
+	}
+	if nested[1].Odds.Value != "1.50" {
+		t.Errorf("Expected place odds 1.50, got %s", nested[1].Odds.Value)
+	}
+	if ticket.Content.Bets[0].Selections[0].Selections[0].MarketID != "1" {
+		t.Error("Win part must keep its market")
+	}
+}
+
+func TestEachWayInvalidTerms(t *testing.T) {
+	builder := NewTicketBuilder(45426, "ew-003")
+	builder.AddEachWay(EachWayTerms{Fraction: "1/4", Places: 3, PlaceMarketID: "40"})
+	builder.AddSingleBet(NewSelection("3", "sr:stage:1", "1", "1", "1.02"), NewStake("cash", "GBP", "5", "total")).
+		AddEachWay(EachWayTerms{Fraction: "1/5", Places: 2, PlaceMarketID: "40"})
+	builder.AddSingleBet(NewSelection("3", "sr:stage:2", "1", "1", "4"), NewStake("cash", "GBP", "5", "total")).
+		AddEachWay(EachWayTerms{Fraction: "5/4", Places: 2, PlaceMarketID: "40"})
+	builder.AddSingleBet(NewSelection("3", "sr:stage:3", "1", "1", "4"), NewStake("cash", "GBP", "5", "total")).
+		AddEachWay(EachWayTerms{Fraction: "1/4", Places: 2})
+
+	_, err := builder.Build("corr-ew")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 4 {
+		t.Fatalf("Expected 4 builder errors, got %v", err)
+	}
+	if errs[0].BetIndex != -1 || errs[1].BetIndex != 0 || errs[2].BetIndex != 1 || errs[3].BetIndex != 2 {
+		t.Errorf("Unexpected error indexes: %v", errs)
+	}
+}
+
+func TestEachWayPlaceSpecifiersAreCanonical(t *testing.T) {
+	terms := EachWayTerms{Fraction: "1/4", Places: 3, PlaceMarketID: "40"}
+	sel := NewSelection("3", "sr:stage:1", "1", "7", "9.00")
+	sel.Specifiers = "variant=sr:place:3 | field=12"
+
+	place, err := terms.PlaceBet(Bet{Selections: []Selection{sel}})
+	if err != nil {
+		t.Fatalf("Failed to derive place bet: %v", err)
+	}
+	if got := place.Selections[0].Specifiers; got != "field=12|places=3|variant=sr:place:3" {
+		t.Errorf("Expected canonical specifiers, got %q", got)
+	}
+
+	sel.Specifiers = "places=5"
+	if _, err := terms.PlaceBet(Bet{Selections: []Selection{sel}}); err == nil {
+		t.Error("Expected an error when the specifiers already set places")
+	}
+}
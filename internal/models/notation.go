@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Notation is a bet in compact notation: "2/4" (doubles from 4 selections),
+// "2,3/5" (doubles and trebles from 5), "B1+2/4" (1 banker with doubles from
+// 4 more) or a preset name such as "Yankee"
+type Notation struct {
+	Preset  string // Canonical preset name; the other fields are unset
+	Bankers int    // Number of bankers, the first selections of the bet
+	Sizes   []int  // Combination sizes, ascending
+	From    int    // Number of selections combined, bankers excluded
+}
+
+var notationPattern = regexp.MustCompile(`^(?:B(\d+)\+)?(\d+(?:,\d+)*)/(\d+)$`)
+
+// ParseNotation parses compact bet notation. Letter case and spaces are
+// ignored, so "b1 + 2/4" equals "B1+2/4".
+func ParseNotation(s string) (Notation, error) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	m := notationPattern.FindStringSubmatch(compact)
+	if m == nil {
+		if preset, ok := LookupPreset(s); ok {
+			return Notation{Preset: preset.Name}, nil
+		}
+		return Notation{}, fmt.Errorf("invalid notation %q: expected e.g. \"2/4\", \"2,3/5\", \"B1+2/4\" or a preset name", s)
+	}
+
+	var n Notation
+	var err error
+	if m[1] != "" {
+		if n.Bankers, err = strconv.Atoi(m[1]); err != nil || n.Bankers < 1 {
+			return Notation{}, fmt.Errorf("invalid notation %q: banker count must be at least 1", s)
+		}
+	}
+	if n.From, err = strconv.Atoi(m[3]); err != nil || n.From < 1 {
+		return Notation{}, fmt.Errorf("invalid notation %q: selection count must be at least 1", s)
+	}
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(m[2], ",") {
+		size, err := strconv.Atoi(field)
+		if err != nil || size < 1 || size > n.From {
+			return Notation{}, fmt.Errorf("invalid notation %q: size %s must be between 1 and %d", s, field, n.From)
+		}
+		if seen[size] {
+			return Notation{}, fmt.Errorf("invalid notation %q: size %d is repeated", s, size)
+		}
+		seen[size] = true
+		n.Sizes = append(n.Sizes, size)
+	}
+	sort.Ints(n.Sizes)
+	return n, nil
+}
+
+// String renders the notation canonically
+func (n Notation) String() string {
+	if n.Preset != "" {
+		return n.Preset
+	}
+	sizes := make([]string, len(n.Sizes))
+	for i, size := range n.Sizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	s := fmt.Sprintf("%s/%d", strings.Join(sizes, ","), n.From)
+	if n.Bankers > 0 {
+		s = fmt.Sprintf("B%d+%s", n.Bankers, s)
+	}
+	return s
+}
+
+// BetNotation renders a built bet in canonical notation: "1/1" for a single,
+// "3/3" for a treble, "2,3/4" for a system and "B1+2/3" for a banker bet.
+// Presets are rendered as the bets they were built into.
+func BetNotation(bet Bet) string {
+	n := Notation{}
+	for _, sel := range bet.Selections {
+		if sel.Type == SelectionTypeSystem {
+			n.Sizes = append([]int{}, sel.Size...)
+			n.From = len(sel.Selections)
+		} else {
+			n.Bankers++
+		}
+	}
+	if n.From == 0 {
+		// A single or an accumulator
+		n = Notation{Sizes: []int{n.Bankers}, From: n.Bankers}
+	}
+	sort.Ints(n.Sizes)
+	return n.String()
+}
+
+// AddNotationBet adds the bet described by compact notation; see
+// ParseNotation. Bankers are the first selections, so "B1+2/4" takes five.
+// "1/1" adds a single and "n/n" an accumulator.
+func (tb *TicketBuilder) AddNotationBet(notation string, selections []Selection, stakes ...Stake) *TicketBuilder {
+	n, err := ParseNotation(notation)
+	if err != nil {
+		tb.fail(tb.beginBet(), "notation", err.Error())
+		return tb
+	}
+	if n.Preset != "" {
+		return tb.AddPresetBet(n.Preset, selections, stakes...)
+	}
+	if count := n.Bankers + n.From; len(selections) != count {
+		tb.fail(tb.beginBet(), "selections", fmt.Sprintf("%s requires exactly %d selections, got %d", n, count, len(selections)))
+		return tb
+	}
+
+	switch {
+	case n.Bankers > 0:
+		return tb.AddBankerSystemBet(selections[:n.Bankers], n.Sizes, selections[n.Bankers:], stakes...)
+	case n.From == 1:
+		return tb.AddSingleBet(selections[0], stakes...)
+	case len(n.Sizes) == 1 && n.Sizes[0] == n.From:
+		return tb.AddAccumulatorBet(selections, stakes...)
+	}
+	return tb.AddSystemBet(n.Sizes, selections, stakes...)
+}
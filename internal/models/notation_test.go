@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestParseNotation(t *testing.T) {
+	valid := map[string]string{
+		"2/4":      "2/4",
+		"3, 2/5":   "2,3/5",
+		"b1 + 2/4": "B1+2/4",
+		"Yankee":   "yankee",
+		"Lucky 15": "lucky15",
+		"4/4":      "4/4",
+	}
+	for input, canonical := range valid {
+		n, err := ParseNotation(input)
+		if err != nil {
+			t.Errorf("ParseNotation(%q) failed: %v", input, err)
+			continue
+		}
+		if n.String() != canonical {
+			t.Errorf("ParseNotation(%q) = %s, expected %s", input, n, canonical)
+		}
+	}
+	for _, input := range []string{"", "5/4", "0/3", "2,2/4", "B0+2/3", "2/", "lucky16"} {
+		if _, err := ParseNotation(input); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestAddNotationBet(t *testing.T) {
+	stake := NewStake("cash", "EUR", 1.00, "unit")
+	ticket, err := NewTicketBuilder(45426, "notation-001").
+		AddNotationBet("1/1", presetSelections(1), stake).
+		AddNotationBet("3/3", presetSelections(3), stake).
+		AddNotationBet("3,2/4", presetSelections(4), stake).
+		AddNotationBet("B1+2/3", presetSelections(4), stake).
+		AddNotationBet("trixie", presetSelections(3), stake).
+		Build("corr-notation-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	expected := []string{"1/1", "3/3", "2,3/4", "B1+2/3", "2,3/3"}
+	for i, bet := range ticket.Content.Bets {
+		if got := BetNotation(bet); got != expected[i] {
+			t.Errorf("Bet %d: expected notation %s, got %s", i, expected[i], got)
+		}
+	}
+	if bankers := ticket.Content.Bets[3].Selections[1:]; len(bankers) != 1 || bankers[0].EventID != "sr:match:1" {
+		t.Errorf("Expected the first selection as banker, got %+v", bankers)
+	}
+
+	_, err = NewTicketBuilder(45426, "notation-002").
+		AddNotationBet("2/4", presetSelections(3), stake).
+		AddNotationBet("2/x", presetSelections(3), stake).
+		Build("corr-notation-002")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 2 || errs[0].Field != "selections" || errs[1].Field != "notation" {
+		t.Errorf("Expected selection count and notation errors, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// Odds-change policies: which changed odds a ticket may be resubmitted at
+const (
+	OddsChangeNone   = "none"   // Never resubmit
+	OddsChangeHigher = "higher" // Resubmit when no selection's odds went down
+	OddsChangeAny    = "any"    // Resubmit at whatever the new odds are
+)
+
+// ParseOddsChangePolicy validates a policy name; an empty name means OddsChangeNone
+func ParseOddsChangePolicy(name string) (string, error) {
+	switch name {
+	case "":
+		return OddsChangeNone, nil
+	case OddsChangeNone, OddsChangeHigher, OddsChangeAny:
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown odds change policy %q (expected none, higher or any)", name)
+}
+
+// OddsChange is a selection MTS rejected because its odds moved
+type OddsChange struct {
+	BetIndex  int    `json:"betIndex"` // Index of the bet in the request
+	BetID     string `json:"betId,omitempty"`
+	Selection int    `json:"selection"` // Index within the bet, counting inside system selections
+	Field     string `json:"field"`
+	OldOdds   string `json:"oldOdds"`
+	NewOdds   string `json:"newOdds"`
+
+	position int // Of the bet in the ticket
+	path     []int
+}
+
+// IsOddsChangeCode reports whether an MTS code is in the odds-change family (-10xx)
+func IsOddsChangeCode(code int) bool {
+	return code <= -1000 && code > -1100
+}
+
+// FindOddsChanges lists the selections of a rejected ticket whose odds MTS
+// reported as changed, with the odds sent and the odds returned in the reply
+func FindOddsChanges(ticket *TicketRequest, resp *TicketResponse) []OddsChange {
+	if resp == nil || resp.Content.Status == "accepted" {
+		return nil
+	}
+	var changes []OddsChange
+	for i, detail := range resp.Content.BetDetails {
+		position, ok := ticket.BetPosition(detail, i)
+		if !ok {
+			continue
+		}
+		bet := ticket.Content.Bets[position]
+		for _, sd := range detail.SelectionDetails {
+			if !IsOddsChangeCode(sd.Code) || sd.Selection.Odds == nil {
+				continue
+			}
+			j, path, ok := findSelection(bet.Selections, sd.Selection)
+			if !ok {
+				continue
+			}
+			old := selectionAt(bet.Selections, path)
+			if old == nil || old.Odds == nil || old.Odds.Value == sd.Selection.Odds.Value {
+				continue
+			}
+			changes = append(changes, OddsChange{
+				BetIndex:  bet.Index,
+				BetID:     bet.BetID,
+				Selection: j,
+				Field:     selectionField(path),
+				OldOdds:   old.Odds.Value,
+				NewOdds:   sd.Selection.Odds.Value,
+				position:  position,
+				path:      path,
+			})
+		}
+	}
+	return changes
+}
+
+// AllowsOddsChanges reports whether policy accepts every change
+func AllowsOddsChanges(policy string, changes []OddsChange) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	switch policy {
+	case OddsChangeAny:
+		return true
+	case OddsChangeHigher:
+		for _, c := range changes {
+			oldOdds, err1 := decimal.Parse(c.OldOdds)
+			newOdds, err2 := decimal.Parse(c.NewOdds)
+			if err1 != nil || err2 != nil || newOdds.LessThan(oldOdds) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ApplyOddsChanges returns a copy of ticket with the new odds under a new
+// ticket and correlation ID; the original ticket is left untouched
+func ApplyOddsChanges(ticket *TicketRequest, changes []OddsChange, ticketID, correlationID string) *TicketRequest {
+	updated := *ticket
+	updated.CorrelationID = correlationID
+	updated.TimestampUTC = time.Now().UnixMilli()
+	updated.Content.TicketID = ticketID
+	updated.Content.Bets = make([]Bet, len(ticket.Content.Bets))
+	for i, bet := range ticket.Content.Bets {
+		bet.Selections = copySelections(bet.Selections)
+		updated.Content.Bets[i] = bet
+	}
+	for _, c := range changes {
+		if sel := selectionAt(updated.Content.Bets[c.position].Selections, c.path); sel != nil {
+			sel.Odds = &Odds{Type: sel.Odds.Type, Value: c.NewOdds}
+		}
+	}
+	return &updated
+}
+
+// copySelections copies selections and any nested system selections
+func copySelections(selections []Selection) []Selection {
+	if selections == nil {
+		return nil
+	}
+	copied := make([]Selection, len(selections))
+	for i, sel := range selections {
+		if sel.Type == SelectionTypeSystem {
+			sel.Selections = copySelections(sel.Selections)
+		}
+		copied[i] = sel
+	}
+	return copied
+}
+
+// selectionAt returns the selection at an index path from findSelection
+func selectionAt(selections []Selection, path []int) *Selection {
+	var sel *Selection
+	for _, index := range path {
+		if index >= len(selections) {
+			return nil
+		}
+		sel = &selections[index]
+		selections = sel.Selections
+	}
+	return sel
+}
@@ -0,0 +1,64 @@
+package models
+
+import "testing"
+
+func TestOddsChangesAndPolicies(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "2.50"),
+		NewSelection("3", "sr:match:3", "1", "1", "3.00"),
+	}
+	ticket, err := NewTicketBuilder(45426, "odds-001").
+		AddSystemBet([]int{2}, sels, NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-odds")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	moved := func(sel Selection, odds string) SelectionDetail {
+		sel.Odds = &Odds{Type: "decimal", Value: odds}
+		return SelectionDetail{Selection: sel, Code: -1001}
+	}
+	resp := &TicketResponse{Content: TicketResponseContent{
+		Status: "rejected",
+		Code:   -1001,
+		BetDetails: []BetDetail{{Code: -1001, SelectionDetails: []SelectionDetail{
+			{Selection: sels[0]},
+			moved(sels[1], "2.60"),
+			moved(sels[2], "2.90"),
+		}}},
+	}}
+
+	changes := FindOddsChanges(ticket, resp)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 odds changes, got %+v", changes)
+	}
+	if c := changes[0]; c.Selection != 1 || c.Field != "selections[0].selections[1]" || c.OldOdds != "2.50" || c.NewOdds != "2.60" {
+		t.Errorf("Unexpected change %+v", c)
+	}
+
+	if AllowsOddsChanges(OddsChangeNone, changes) || AllowsOddsChanges(OddsChangeHigher, changes) || !AllowsOddsChanges(OddsChangeAny, changes) {
+		t.Error("Expected only the any policy to accept a shortened price")
+	}
+	if !AllowsOddsChanges(OddsChangeHigher, changes[:1]) {
+		t.Error("Expected the higher policy to accept a longer price")
+	}
+
+	resubmit := ApplyOddsChanges(ticket, changes, "odds-001-odds1", "corr-resubmit")
+	nested := resubmit.Content.Bets[0].Selections[0].Selections
+	if resubmit.Content.TicketID != "odds-001-odds1" || nested[1].Odds.Value != "2.60" || nested[2].Odds.Value != "2.90" {
+		t.Errorf("Unexpected resubmitted ticket %+v", resubmit.Content)
+	}
+	if ticket.Content.Bets[0].Selections[0].Selections[1].Odds.Value != "2.50" {
+		t.Error("ApplyOddsChanges must not modify the original ticket")
+	}
+}
+
+func TestParseOddsChangePolicy(t *testing.T) {
+	if p, err := ParseOddsChangePolicy(""); err != nil || p != OddsChangeNone {
+		t.Errorf("Expected empty policy to mean none, got %q, %v", p, err)
+	}
+	if _, err := ParseOddsChangePolicy("lower"); err == nil {
+		t.Error("Expected error for an unknown policy")
+	}
+}
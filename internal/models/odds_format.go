@@ -0,0 +1,69 @@
+package models
+
+import (
+	"github.com/gdsZyy/mts-service/internal/odds"
+)
+
+// WithOddsFormat returns a copy of the response with every selection's odds
+// rendered in format; the Odds type is set to the format name. The receiver is
+// left untouched because responses may be shared with the idempotency cache.
+// Odds that cannot be converted are kept in decimal.
+func (r *TicketResponse) WithOddsFormat(format odds.Format) *TicketResponse {
+	if r == nil || format == odds.Decimal || format == "" {
+		return r
+	}
+
+	rendered := *r
+	details := make([]BetDetail, len(r.Content.BetDetails))
+	for i, detail := range r.Content.BetDetails {
+		selections := make([]SelectionDetail, len(detail.SelectionDetails))
+		for j, sd := range detail.SelectionDetails {
+			sd.Selection = renderSelectionOdds(sd.Selection, format)
+			selections[j] = sd
+		}
+		detail.SelectionDetails = selections
+		details[i] = detail
+	}
+	if r.Content.BetDetails == nil {
+		details = nil
+	}
+	rendered.Content.BetDetails = details
+	return &rendered
+}
+
+// RenderLineOdds rewrites the combined and per-selection odds of expanded
+// lines in format
+func RenderLineOdds(bets []BetLines, format odds.Format) {
+	if format == odds.Decimal || format == "" {
+		return
+	}
+	for i := range bets {
+		for j := range bets[i].Lines {
+			line := &bets[i].Lines[j]
+			if value, err := odds.Render(line.Odds, format); err == nil {
+				line.Odds = value
+			}
+			for k, sel := range line.Selections {
+				line.Selections[k] = renderSelectionOdds(sel, format)
+			}
+		}
+	}
+}
+
+// renderSelectionOdds returns a copy of sel with its odds (and those of any
+// nested selections) in format
+func renderSelectionOdds(sel Selection, format odds.Format) Selection {
+	if len(sel.Selections) > 0 {
+		nested := make([]Selection, len(sel.Selections))
+		for i, child := range sel.Selections {
+			nested[i] = renderSelectionOdds(child, format)
+		}
+		sel.Selections = nested
+	}
+	if sel.Odds != nil && (sel.Odds.Type == "" || sel.Odds.Type == string(odds.Decimal)) {
+		if value, err := odds.Render(sel.Odds.Value, format); err == nil {
+			sel.Odds = &Odds{Type: string(format), Value: value}
+		}
+	}
+	return sel
+}
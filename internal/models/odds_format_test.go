@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/gdsZyy/mts-service/internal/odds"
+)
+
+func TestWithOddsFormatCopiesResponse(t *testing.T) {
+	resp := &TicketResponse{
+		Content: TicketResponseContent{
+			Status: "accepted",
+			BetDetails: []BetDetail{{
+				SelectionDetails: []SelectionDetail{
+					{Selection: NewSelection("3", "sr:match:1", "1", "1", "3.5")},
+				},
+			}},
+		},
+	}
+
+	rendered := resp.WithOddsFormat(odds.Fractional)
+	got := rendered.Content.BetDetails[0].SelectionDetails[0].Selection.Odds
+	if got.Type != "fractional" || got.Value != "5/2" {
+		t.Errorf("Expected fractional 5/2, got %+v", got)
+	}
+	original := resp.Content.BetDetails[0].SelectionDetails[0].Selection.Odds
+	if original.Type != "decimal" || original.Value != "3.5" {
+		t.Errorf("Original response was modified: %+v", original)
+	}
+	if resp.WithOddsFormat(odds.Decimal) != resp {
+		t.Error("Decimal format should return the response unchanged")
+	}
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset is a named bet over a set of selections, e.g. a Yankee. Most presets
+// are one system bet over every selection; others are made of several bets
+// over subsets of the selections, given by Parts.
+type Preset struct {
+	Name        string       `json:"name"`                  // Canonical type, e.g. "lucky15"
+	Label       string       `json:"label"`                 // Display name, e.g. "Lucky 15"
+	Aliases     []string     `json:"aliases,omitempty"`     // Other accepted types
+	Selections  int          `json:"selections"`            // Required number of selections; 0 for any number from 2
+	Sizes       []int        `json:"sizes,omitempty"`       // Combination sizes; empty when they follow from the selection count
+	Singles     bool         `json:"singles"`               // Whether the preset includes singles
+	Parts       []PresetPart `json:"parts,omitempty"`       // Bets of a preset made of several bets
+	Lines       int64        `json:"lines,omitempty"`       // Lines in total, for a fixed number of selections
+	Unsupported string       `json:"unsupported,omitempty"` // Why the preset cannot be placed
+}
+
+// PresetPart is one bet of a preset made of several bets
+type PresetPart struct {
+	Legs  []int `json:"legs"`  // Positions of the bet's selections among the preset's selections
+	Sizes []int `json:"sizes"` // Combination sizes; the size of all legs alone is an accumulator
+}
+
+// presets is the preset registry, in display order
+var presets = []Preset{
+	{Name: "trixie", Label: "Trixie", Selections: 3, Sizes: []int{2, 3}},
+	{Name: "patent", Label: "Patent", Selections: 3, Sizes: []int{1, 2, 3}, Singles: true},
+	{Name: "yankee", Label: "Yankee", Selections: 4, Sizes: []int{2, 3, 4}},
+	{Name: "lucky15", Label: "Lucky 15", Selections: 4, Sizes: []int{1, 2, 3, 4}, Singles: true},
+	{Name: "super_yankee", Label: "Super Yankee", Aliases: []string{"canadian"}, Selections: 5, Sizes: []int{2, 3, 4, 5}},
+	{Name: "lucky31", Label: "Lucky 31", Selections: 5, Sizes: []int{1, 2, 3, 4, 5}, Singles: true},
+	{Name: "heinz", Label: "Heinz", Selections: 6, Sizes: []int{2, 3, 4, 5, 6}},
+	{Name: "lucky63", Label: "Lucky 63", Selections: 6, Sizes: []int{1, 2, 3, 4, 5, 6}, Singles: true},
+	{Name: "super_heinz", Label: "Super Heinz", Selections: 7, Sizes: []int{2, 3, 4, 5, 6, 7}},
+	{Name: "goliath", Label: "Goliath", Selections: 8, Sizes: []int{2, 3, 4, 5, 6, 7, 8}},
+	// Two Patents on selections 1-3 and 4-6, a Yankee on 2-5 and a six-fold
+	{Name: "alphabet", Label: "Alphabet", Selections: 6, Singles: true, Parts: []PresetPart{
+		{Legs: []int{0, 1, 2}, Sizes: []int{1, 2, 3}},
+		{Legs: []int{3, 4, 5}, Sizes: []int{1, 2, 3}},
+		{Legs: []int{1, 2, 3, 4}, Sizes: []int{2, 3, 4}},
+		{Legs: []int{0, 1, 2, 3, 4, 5}, Sizes: []int{6}},
+	}},
+	// Trebles on the rows, columns and diagonals of a 3x3 grid of selections
+	{Name: "union_jack", Label: "Union Jack", Selections: 9, Parts: []PresetPart{
+		{Legs: []int{0, 1, 2}, Sizes: []int{3}},
+		{Legs: []int{3, 4, 5}, Sizes: []int{3}},
+		{Legs: []int{6, 7, 8}, Sizes: []int{3}},
+		{Legs: []int{0, 3, 6}, Sizes: []int{3}},
+		{Legs: []int{1, 4, 7}, Sizes: []int{3}},
+		{Legs: []int{2, 5, 8}, Sizes: []int{3}},
+		{Legs: []int{0, 4, 8}, Sizes: []int{3}},
+		{Legs: []int{2, 4, 6}, Sizes: []int{3}},
+	}},
+	{Name: "flag", Label: "Flag", Selections: 4, Sizes: []int{2, 3, 4},
+		Unsupported: "a Flag adds single stakes about pairs to a Yankee; MTS does not accept conditional bets"},
+	{Name: "super_flag", Label: "Super Flag", Selections: 5, Sizes: []int{2, 3, 4, 5},
+		Unsupported: "a Super Flag adds single stakes about pairs to a Super Yankee; MTS does not accept conditional bets"},
+	{Name: "full_cover", Label: "Full cover", Aliases: []string{"full_cover_without_singles"}},
+	{Name: "full_cover_singles", Label: "Full cover with singles", Aliases: []string{"full_cover_with_singles"}, Singles: true},
+}
+
+var presetsByKey = indexPresets()
+
+func indexPresets() map[string]int {
+	index := make(map[string]int)
+	for i := range presets {
+		p := &presets[i]
+		if p.Selections > 0 && p.Unsupported == "" {
+			p.Lines = presetLines(p.layout(p.Selections))
+		}
+		for _, name := range append([]string{p.Name}, p.Aliases...) {
+			index[presetKey(name)] = i
+		}
+	}
+	return index
+}
+
+// presetKey folds case, spaces, hyphens and underscores, so "Lucky 15",
+// "lucky_15" and "lucky15" name the same preset
+func presetKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// Presets returns the preset registry
+func Presets() []Preset {
+	return append([]Preset{}, presets...)
+}
+
+// LookupPreset finds a preset by name or alias
+func LookupPreset(name string) (Preset, bool) {
+	i, ok := presetsByKey[presetKey(name)]
+	if !ok {
+		return Preset{}, false
+	}
+	return presets[i], true
+}
+
+// Layout returns the bets of the preset over count selections
+func (p Preset) Layout(count int) ([]PresetPart, error) {
+	if p.Unsupported != "" {
+		return nil, fmt.Errorf("%s cannot be placed: %s", p.Name, p.Unsupported)
+	}
+	if p.Selections > 0 && count != p.Selections {
+		return nil, fmt.Errorf("%s requires exactly %d selections, got %d", p.Name, p.Selections, count)
+	}
+	if p.Selections == 0 && count < 2 {
+		return nil, fmt.Errorf("%s requires at least 2 selections, got %d", p.Name, count)
+	}
+	return p.layout(count), nil
+}
+
+func (p Preset) layout(count int) []PresetPart {
+	if len(p.Parts) > 0 {
+		return p.Parts
+	}
+	part := PresetPart{Legs: make([]int, count), Sizes: p.Sizes}
+	for i := range part.Legs {
+		part.Legs[i] = i
+	}
+	if len(part.Sizes) == 0 {
+		first := 2
+		if p.Singles {
+			first = 1
+		}
+		for size := first; size <= count; size++ {
+			part.Sizes = append(part.Sizes, size)
+		}
+	}
+	return []PresetPart{part}
+}
+
+// presetLines counts the lines of a preset layout
+func presetLines(parts []PresetPart) int64 {
+	var lines int64
+	for _, part := range parts {
+		for _, size := range part.Sizes {
+			lines += binomial(len(part.Legs), size)
+		}
+	}
+	return lines
+}
+
+func binomial(n, k int) int64 {
+	result := int64(1)
+	for i := 1; i <= k; i++ {
+		result = result * int64(n-k+i) / int64(i)
+	}
+	return result
+}
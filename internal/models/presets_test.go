@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func presetSelections(n int) []Selection {
+	sels := make([]Selection, n)
+	for i := range sels {
+		sels[i] = NewSelection("3", fmt.Sprintf("sr:match:%d", i+1), "1", "1", "2.00")
+	}
+	return sels
+}
+
+func TestPresetRegistry(t *testing.T) {
+	expected := map[string]int64{
+		"trixie": 4, "Lucky 15": 15, "canadian": 26, "lucky_63": 63, "goliath": 247,
+		"alphabet": 26, "union-jack": 8,
+	}
+	for name, lines := range expected {
+		preset, ok := LookupPreset(name)
+		if !ok {
+			t.Errorf("Expected preset %q", name)
+			continue
+		}
+		if preset.Lines != lines {
+			t.Errorf("%s: expected %d lines, got %d", name, lines, preset.Lines)
+		}
+	}
+	if _, ok := LookupPreset("lucky16"); ok {
+		t.Error("Expected lucky16 to be unknown")
+	}
+
+	cover, _ := LookupPreset("full_cover_singles")
+	layout, err := cover.Layout(4)
+	if err != nil || presetLines(layout) != 15 {
+		t.Errorf("Expected full cover with singles of 4 to have 15 lines, got %v, %v", layout, err)
+	}
+	flag, _ := LookupPreset("flag")
+	if _, err := flag.Layout(4); err == nil {
+		t.Error("Expected the flag to be rejected")
+	}
+}
+
+func TestAddPresetBet(t *testing.T) {
+	stake := NewStake("cash", "EUR", 1.00, "unit")
+	ticket, err := NewTicketBuilder(45426, "preset-001").
+		AddPresetBet("full_cover", presetSelections(5), stake).
+		AddPresetBet("alphabet", presetSelections(6), stake).
+		SetBetID("abc").
+		Build("corr-preset-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	bets := ticket.Content.Bets
+	if len(bets) != 5 {
+		t.Fatalf("Expected 5 bets, got %d", len(bets))
+	}
+	if size := bets[0].Selections[0].Size; len(size) != 4 || size[0] != 2 {
+		t.Errorf("Expected full cover sizes 2-5, got %v", size)
+	}
+	var lines int64
+	for i, bet := range bets[1:] {
+		if bet.Index != 1 {
+			t.Errorf("Expected alphabet bet %d to have index 1, got %d", i, bet.Index)
+		}
+		n, _ := CountLines(bet)
+		lines += n
+	}
+	if lines != 26 {
+		t.Errorf("Expected 26 alphabet lines, got %d", lines)
+	}
+	if bets[1].BetID != "abc" || bets[2].BetID != "abc-2" || bets[4].Kind() != BetKindAccumulator {
+		t.Errorf("Unexpected alphabet bets %+v", bets[1:])
+	}
+	if legs := RequestLegs(bets[2]); legs[0].Field != "selections[3]" {
+		t.Errorf("Expected the second patent to start at selections[3], got %s", legs[0].Field)
+	}
+
+	_, err = NewTicketBuilder(45426, "preset-002").
+		AddPresetBet("union_jack", presetSelections(8), stake).
+		AddPresetBet("super_flag", presetSelections(5), stake).
+		AddPresetBet("lucky16", presetSelections(4), stake).
+		Build("corr-preset-002")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 3 || errs[2].Field != "type" {
+		t.Errorf("Expected three preset errors, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// Quote summarises the stake and potential return of a ticket without sending it
+type Quote struct {
+	TicketID   string       `json:"ticketId"`
+	Currency   string       `json:"currency"`
+	TotalStake string       `json:"totalStake"`
+	Lines      int64        `json:"lines"`
+	MinReturn  string       `json:"minReturn"` // Smallest return if at least one line wins
+	MaxReturn  string       `json:"maxReturn"` // Return if every line wins
+	Stakes     []StakeQuote `json:"stakes"`    // Total staked per stake type
+	Bets       []BetQuote   `json:"bets"`
+}
+
+// BetQuote is the quote for one bet of a ticket
+type BetQuote struct {
+	Index      int          `json:"index"`
+	Part       string       `json:"part,omitempty"` // "win" or "place" for each-way bets
+	Lines      int64        `json:"lines"`
+	UnitStake  string       `json:"unitStake"`
+	TotalStake string       `json:"totalStake"`
+	MinReturn  string       `json:"minReturn"`
+	MaxReturn  string       `json:"maxReturn"`
+	Stakes     []StakeQuote `json:"stakes"`
+}
+
+// StakeQuote is the amount staked of one stake type
+type StakeQuote struct {
+	Type       string `json:"type"`
+	UnitStake  string `json:"unitStake,omitempty"`
+	TotalStake string `json:"totalStake"`
+}
+
+// oddsSummary describes every line a selection contributes: how many there
+// are, the sum of their combined odds and the lowest combined odds
+type oddsSummary struct {
+	lines int64
+	sum   decimal.Decimal
+	min   decimal.Decimal
+}
+
+// QuoteTicket calculates stakes and potential returns for every bet of a ticket.
+// Returns are rounded down to MTS precision so they are never overstated, and
+// exclude free-bet stake, which is not paid back on a winning line.
+// Each-way bets are quoted as their win and place parts, so the stake doubles.
+func QuoteTicket(ticket *TicketRequest) (*Quote, error) {
+	quote := &Quote{TicketID: ticket.Content.TicketID}
+	totalStake := decimal.Zero
+	maxReturn := decimal.Zero
+	var minReturn decimal.Decimal
+	var stakeByType []StakeQuote
+
+	for i, bet := range ticket.Content.Bets {
+		bq, stake, minRet, maxRet, currency, err := quoteBet(bet)
+		if err != nil {
+			return nil, fmt.Errorf("bet[%d]: %w", i, err)
+		}
+		if quote.Currency == "" {
+			quote.Currency = currency
+		} else if currency != quote.Currency {
+			return nil, fmt.Errorf("bet[%d]: currency %s differs from %s", i, currency, quote.Currency)
+		}
+
+		bq.Index = i
+		bq.Part = bet.Part
+		quote.Bets = append(quote.Bets, *bq)
+		for _, sq := range bq.Stakes {
+			stakeByType = addStakeQuote(stakeByType, sq)
+		}
+		quote.Lines += bq.Lines
+		totalStake = totalStake.Add(stake)
+		maxReturn = maxReturn.Add(maxRet)
+		if i == 0 || minRet.LessThan(minReturn) {
+			minReturn = minRet
+		}
+	}
+
+	quote.TotalStake = totalStake.StringMTS()
+	quote.MinReturn = minReturn.StringMTS()
+	quote.MaxReturn = maxReturn.StringMTS()
+	quote.Stakes = stakeByType
+	return quote, nil
+}
+
+// addStakeQuote adds the total of sq to the entry of its type in totals
+func addStakeQuote(totals []StakeQuote, sq StakeQuote) []StakeQuote {
+	amount := decimal.MustParse(sq.TotalStake)
+	for i := range totals {
+		if totals[i].Type == sq.Type {
+			totals[i].TotalStake = decimal.MustParse(totals[i].TotalStake).Add(amount).StringMTS()
+			return totals
+		}
+	}
+	return append(totals, StakeQuote{Type: sq.Type, TotalStake: amount.StringMTS()})
+}
+
+func quoteBet(bet Bet) (bq *BetQuote, totalStake, minReturn, maxReturn decimal.Decimal, currency string, err error) {
+	if len(bet.Selections) == 0 {
+		err = fmt.Errorf("bet has no selections")
+		return
+	}
+	if len(bet.Stake) == 0 {
+		err = fmt.Errorf("bet has no stake")
+		return
+	}
+
+	// A bet's lines are the cross product of its legs: each standard
+	// selection (e.g. a banker) is in every line of the system selection
+	summary := oddsSummary{lines: 1, sum: decimal.One, min: decimal.One}
+	for j, sel := range bet.Selections {
+		leg, legErr := summariseSelection(sel)
+		if legErr != nil {
+			err = fmt.Errorf("selection[%d]: %w", j, legErr)
+			return
+		}
+		summary.lines *= leg.lines
+		summary.sum = summary.sum.Mul(leg.sum)
+		summary.min = summary.min.Mul(leg.min)
+	}
+	if summary.lines == 0 {
+		err = fmt.Errorf("bet has no lines")
+		return
+	}
+
+	lines := decimal.NewFromInt(summary.lines)
+	unitStake := decimal.Zero
+	totalStake = decimal.Zero
+	freeStake := decimal.Zero
+	stakes := make([]StakeQuote, 0, len(bet.Stake))
+	for k, stake := range bet.Stake {
+		amount, parseErr := decimal.Parse(stake.Amount)
+		if parseErr != nil {
+			err = fmt.Errorf("stake[%d]: %w", k, parseErr)
+			return
+		}
+		if currency == "" {
+			currency = stake.Currency
+		} else if stake.Currency != currency {
+			err = fmt.Errorf("stake[%d]: currency %s differs from %s", k, stake.Currency, currency)
+			return
+		}
+		var unit, total decimal.Decimal
+		if stake.Mode == "unit" {
+			unit, total = amount, amount.Mul(lines)
+		} else {
+			unit, total = amount.Div(lines, decimal.MTSScale, decimal.RoundDown), amount
+		}
+		unitStake = unitStake.Add(unit)
+		totalStake = totalStake.Add(total)
+		if !ReturnsStake(stake.Type) {
+			freeStake = freeStake.Add(total)
+		}
+		stakes = append(stakes, StakeQuote{Type: stake.Type, UnitStake: unit.StringMTS(), TotalStake: total.StringMTS()})
+	}
+
+	// Multiply before dividing so total-mode returns are not skewed by a rounded
+	// unit stake. Each line pays stake × odds less its share of free-bet stake:
+	// the lowest line returns (total × min − free) / lines, all lines together
+	// total × sum / lines − free.
+	minReturn = totalStake.Mul(summary.min).Sub(freeStake).Div(lines, decimal.MTSScale, decimal.RoundDown)
+	maxReturn = totalStake.Mul(summary.sum).Div(lines, decimal.MTSScale, decimal.RoundDown).Sub(freeStake)
+
+	bq = &BetQuote{
+		Lines:      summary.lines,
+		UnitStake:  unitStake.StringMTS(),
+		TotalStake: totalStake.StringMTS(),
+		MinReturn:  minReturn.StringMTS(),
+		MaxReturn:  maxReturn.StringMTS(),
+		Stakes:     stakes,
+	}
+	return
+}
+
+// summariseSelection returns the lines a selection expands to; a standard
+// selection is one line at its own odds, a system selection is every
+// combination of its nested selections for each of its sizes
+func summariseSelection(sel Selection) (oddsSummary, error) {
+	if sel.Type != "system" {
+		if sel.Odds == nil {
+			return oddsSummary{}, fmt.Errorf("odds is required")
+		}
+		odds, err := decimal.Parse(sel.Odds.Value)
+		if err != nil {
+			return oddsSummary{}, err
+		}
+		return oddsSummary{lines: 1, sum: odds, min: odds}, nil
+	}
+
+	if len(sel.Size) == 0 {
+		return oddsSummary{}, fmt.Errorf("size is required")
+	}
+
+	children := make([]oddsSummary, len(sel.Selections))
+	for i, child := range sel.Selections {
+		summary, err := summariseSelection(child)
+		if err != nil {
+			return oddsSummary{}, fmt.Errorf("selections[%d]: %w", i, err)
+		}
+		children[i] = summary
+	}
+
+	// Elementary symmetric polynomials give, for every size k, the number of
+	// lines and the sum of their odds without enumerating the combinations
+	n := len(children)
+	lineCounts := make([]int64, n+1)
+	oddsSums := make([]decimal.Decimal, n+1)
+	lineCounts[0] = 1
+	oddsSums[0] = decimal.One
+	for k := 1; k <= n; k++ {
+		oddsSums[k] = decimal.Zero
+	}
+	for _, child := range children {
+		for k := n; k >= 1; k-- {
+			lineCounts[k] += lineCounts[k-1] * child.lines
+			oddsSums[k] = oddsSums[k].Add(oddsSums[k-1].Mul(child.sum))
+		}
+	}
+
+	// The cheapest line of size k combines the k lowest-priced selections
+	mins := make([]decimal.Decimal, n)
+	for i, child := range children {
+		mins[i] = child.min
+	}
+	sort.Slice(mins, func(i, j int) bool { return mins[i].LessThan(mins[j]) })
+
+	result := oddsSummary{sum: decimal.Zero}
+	for i, size := range sel.Size {
+		if size < 1 || size > n {
+			return oddsSummary{}, fmt.Errorf("size[%d]: %d is out of range 1-%d", i, size, n)
+		}
+		result.lines += lineCounts[size]
+		result.sum = result.sum.Add(oddsSums[size])
+
+		lowest := decimal.One
+		for _, m := range mins[:size] {
+			lowest = lowest.Mul(m)
+		}
+		if i == 0 || lowest.LessThan(result.min) {
+			result.min = lowest
+		}
+	}
+	return result, nil
+}
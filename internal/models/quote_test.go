@@ -0,0 +1,126 @@
+package models
+
+import "testing"
+
+func quoteBuilt(t *testing.T, builder *TicketBuilder) *Quote {
+	t.Helper()
+	ticket, err := builder.Build("corr-quote")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	quote, err := QuoteTicket(ticket)
+	if err != nil {
+		t.Fatalf("Failed to quote ticket: %v", err)
+	}
+	return quote
+}
+
+func TestQuoteSingleAndAccumulator(t *testing.T) {
+	builder := NewTicketBuilder(45426, "quote-001")
+	builder.AddSingleBet(NewSelection("3", "sr:match:1", "1", "1", "2.50"), NewStake("cash", "EUR", "10", "total"))
+	builder.AddAccumulatorBet([]Selection{
+		NewSelection("3", "sr:match:2", "1", "1", "1.50"),
+		NewSelection("3", "sr:match:3", "1", "1", "2.10"),
+	}, NewStake("cash", "EUR", "5", "total"))
+
+	quote := quoteBuilt(t, builder)
+	if quote.Lines != 2 || quote.TotalStake != "15" {
+		t.Errorf("Expected 2 lines and stake 15, got %d and %s", quote.Lines, quote.TotalStake)
+	}
+	if quote.Bets[0].MaxReturn != "25" || quote.Bets[1].MaxReturn != "15.75" {
+		t.Errorf("Unexpected bet returns: %s, %s", quote.Bets[0].MaxReturn, quote.Bets[1].MaxReturn)
+	}
+	if quote.MinReturn != "15.75" || quote.MaxReturn != "40.75" {
+		t.Errorf("Expected min 15.75 and max 40.75, got %s and %s", quote.MinReturn, quote.MaxReturn)
+	}
+}
+
+func TestQuoteTrixieUnitStake(t *testing.T) {
+	builder := NewTicketBuilder(45426, "quote-002")
+	builder.AddTrixieBet([]Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2"),
+		NewSelection("3", "sr:match:2", "1", "1", "3"),
+		NewSelection("3", "sr:match:3", "1", "1", "4"),
+	}, NewStake("cash", "EUR", "1", "unit"))
+
+	// Doubles 6 + 8 + 12 and treble 24
+	quote := quoteBuilt(t, builder)
+	if quote.Lines != 4 || quote.TotalStake != "4" {
+		t.Errorf("Expected 4 lines and stake 4, got %d and %s", quote.Lines, quote.TotalStake)
+	}
+	if quote.MinReturn != "6" || quote.MaxReturn != "50" {
+		t.Errorf("Expected min 6 and max 50, got %s and %s", quote.MinReturn, quote.MaxReturn)
+	}
+}
+
+func TestQuoteBankerTotalStake(t *testing.T) {
+	builder := NewTicketBuilder(45426, "quote-003")
+	builder.AddBankerSystemBet(
+		[]Selection{NewSelection("3", "sr:match:1", "1", "1", "1.5")},
+		[]int{2},
+		[]Selection{
+			NewSelection("3", "sr:match:2", "1", "1", "2"),
+			NewSelection("3", "sr:match:3", "1", "1", "2"),
+			NewSelection("3", "sr:match:4", "1", "1", "2"),
+		},
+		NewStake("cash", "EUR", "10", "total"),
+	)
+
+	// 3 lines at 1.5 × 2 × 2 = 6, unit stake 10/3
+	quote := quoteBuilt(t, builder)
+	bet := quote.Bets[0]
+	if bet.Lines != 3 || bet.UnitStake != "3.33333333" {
+		t.Errorf("Expected 3 lines at 3.33333333, got %d at %s", bet.Lines, bet.UnitStake)
+	}
+	if bet.MinReturn != "20" || bet.MaxReturn != "60" {
+		t.Errorf("Expected min 20 and max 60, got %s and %s", bet.MinReturn, bet.MaxReturn)
+	}
+}
+
+func TestQuoteGoliathLineCount(t *testing.T) {
+	var selections []Selection
+	for i := 0; i < 8; i++ {
+		selections = append(selections, NewSelection("3", "sr:match:"+string(rune('1'+i)), "1", "1", "2"))
+	}
+	builder := NewTicketBuilder(45426, "quote-004")
+	builder.AddGoliathBet(selections, NewStake("cash", "EUR", "0.1", "unit"))
+
+	// 247 lines; every line of size k pays 2^k, so the total is 3^8 - 1 - 8×2
+	quote := quoteBuilt(t, builder)
+	if quote.Lines != 247 || quote.TotalStake != "24.7" {
+		t.Errorf("Expected 247 lines and stake 24.7, got %d and %s", quote.Lines, quote.TotalStake)
+	}
+	if quote.MaxReturn != "654.4" {
+		t.Errorf("Expected max return 654.4, got %s", quote.MaxReturn)
+	}
+}
+
+func TestQuoteMixedCashAndFreeStake(t *testing.T) {
+	builder := NewTicketBuilder(45426, "quote-005")
+	builder.AddTrixieBet([]Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2"),
+		NewSelection("3", "sr:match:2", "1", "1", "3"),
+		NewSelection("3", "sr:match:3", "1", "1", "4"),
+	}, NewStake("cash", "EUR", "1", "unit"), NewStake("free", "EUR", "0.5", "unit"))
+
+	// Each line pays 1.5 × odds less the 0.5 free stake: 8.5, 11.5, 17.5 and 35.5
+	quote := quoteBuilt(t, builder)
+	if quote.TotalStake != "6" || quote.Bets[0].UnitStake != "1.5" {
+		t.Errorf("Expected stake 6 (unit 1.5), got %s (unit %s)", quote.TotalStake, quote.Bets[0].UnitStake)
+	}
+	if quote.MinReturn != "8.5" || quote.MaxReturn != "73" {
+		t.Errorf("Expected min 8.5 and max 73, got %s and %s", quote.MinReturn, quote.MaxReturn)
+	}
+	if len(quote.Stakes) != 2 || quote.Stakes[0] != (StakeQuote{Type: "cash", TotalStake: "4"}) || quote.Stakes[1] != (StakeQuote{Type: "free", TotalStake: "2"}) {
+		t.Errorf("Unexpected stake breakdown %+v", quote.Stakes)
+	}
+
+	lines, err := ExpandBet(Bet{Selections: []Selection{{Type: "system", Size: []int{3}, Selections: []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2"),
+		NewSelection("3", "sr:match:2", "1", "1", "3"),
+		NewSelection("3", "sr:match:3", "1", "1", "4"),
+	}}}, Stake: []Stake{NewStake("cash", "EUR", "1", "unit"), NewStake("free", "EUR", "0.5", "unit")}})
+	if err != nil || len(lines) != 1 || lines[0].Stake != "1.5" || lines[0].Return != "35.5" {
+		t.Errorf("Expected one line staking 1.5 to return 35.5, got %+v (%v)", lines, err)
+	}
+}
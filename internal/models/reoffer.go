@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// alternativeStakeScale is the number of decimal places in AlternativeStake.Stake,
+// which MTS sends as an integer in 1/10,000 of the currency unit
+const alternativeStakeScale = 4
+
+// Amount returns the offered total stake of the bet
+func (a *AlternativeStake) Amount() decimal.Decimal {
+	return decimal.New(a.Stake, alternativeStakeScale)
+}
+
+// Reoffer is a lower stake offered by MTS for one or more bets of a rejected
+// ticket. Accepting it places a copy of the ticket with the offered stakes
+// under a new ticket ID.
+type Reoffer struct {
+	TicketID  string       `json:"ticketId"` // The rejected ticket
+	ExpiresAt time.Time    `json:"expiresAt"`
+	Bets      []ReofferBet `json:"bets"`
+
+	ticket *TicketRequest
+}
+
+// ReofferBet is the offered stake of one bet, in the mode it was placed in
+type ReofferBet struct {
+	Index         int     `json:"index"` // Position of the bet in the ticket
+	BetID         string  `json:"betId,omitempty"`
+	OriginalStake []Stake `json:"originalStake"`
+	Stake         []Stake `json:"stake"`
+}
+
+// NewReoffer collects the alternative stakes of a ticket response. It returns
+// nil when MTS offered none. Bets with several stake entries keep their
+// proportions; amounts are rounded down to MTS precision.
+func NewReoffer(ticket *TicketRequest, resp *TicketResponse, expiresAt time.Time) (*Reoffer, error) {
+	if resp == nil || resp.Content.Status == "accepted" {
+		return nil, nil
+	}
+	reoffer := &Reoffer{TicketID: ticket.Content.TicketID, ExpiresAt: expiresAt, ticket: ticket}
+	for i, detail := range resp.Content.BetDetails {
+		if detail.AlternativeStake == nil {
+			continue
+		}
+		position, ok := ticket.BetPosition(detail, i)
+		if !ok {
+			return nil, fmt.Errorf("betDetails[%d]: no such bet in the ticket", i)
+		}
+		bet := ticket.Content.Bets[position]
+		stakes, err := scaleStakes(bet, detail.AlternativeStake.Amount())
+		if err != nil {
+			return nil, fmt.Errorf("bet[%d]: %w", position, err)
+		}
+		reoffer.Bets = append(reoffer.Bets, ReofferBet{Index: position, BetID: bet.BetID, OriginalStake: bet.Stake, Stake: stakes})
+	}
+	if len(reoffer.Bets) == 0 {
+		return nil, nil
+	}
+	return reoffer, nil
+}
+
+// Expired reports whether the offer can no longer be accepted at now
+func (r *Reoffer) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// NewTicketID is the ID the accepted ticket is placed under, derived from the
+// rejected ticket so the two stay linked
+func (r *Reoffer) NewTicketID() string {
+	return r.TicketID + "-reoffer"
+}
+
+// Accept returns a copy of the original ticket holding only the bets MTS
+// offered a stake for, at the offered stakes. Bets without an offer were not
+// accepted at any stake and are left out.
+func (r *Reoffer) Accept(correlationID string) *TicketRequest {
+	ticket := *r.ticket
+	ticket.CorrelationID = correlationID
+	ticket.TimestampUTC = time.Now().UnixMilli()
+	ticket.Content.TicketID = r.NewTicketID()
+	ticket.Content.Bets = make([]Bet, 0, len(r.Bets))
+	for _, offered := range r.Bets {
+		bet := r.ticket.Content.Bets[offered.Index]
+		bet.Stake = offered.Stake
+		ticket.Content.Bets = append(ticket.Content.Bets, bet)
+	}
+	return &ticket
+}
+
+// scaleStakes scales every stake entry of a bet so the bet's total stake
+// becomes total
+func scaleStakes(bet Bet, total decimal.Decimal) ([]Stake, error) {
+	lines, err := ExpandBet(bet)
+	if err != nil {
+		return nil, err
+	}
+
+	current := decimal.Zero
+	amounts := make([]decimal.Decimal, len(bet.Stake))
+	for i, stake := range bet.Stake {
+		if amounts[i], err = decimal.Parse(stake.Amount); err != nil {
+			return nil, fmt.Errorf("stake[%d]: invalid amount %q", i, stake.Amount)
+		}
+		current = current.Add(amounts[i])
+	}
+	if bet.Stake[0].Mode == "unit" {
+		current = current.MulInt(int64(len(lines)))
+	}
+	if current.Sign() <= 0 {
+		return nil, fmt.Errorf("stake must be greater than 0")
+	}
+
+	scaled := make([]Stake, len(bet.Stake))
+	for i, stake := range bet.Stake {
+		amount := amounts[i].Mul(total).Div(current, decimal.MTSScale, decimal.RoundDown)
+		stake.Amount = amount.StringMTS()
+		scaled[i] = stake
+	}
+	return scaled, nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReofferScalesStakes(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+	}
+	ticket, err := NewTicketBuilder(45426, "reoffer-001").
+		AddSingleBet(sels[0], NewStake("cash", "EUR", "6", "total"), NewStake("free", "EUR", "4", "total")).
+		AddTrixieBet(sels, NewStake("cash", "EUR", "2", "unit")).
+		AddAccumulatorBet(sels[1:], NewStake("cash", "EUR", "5", "total")).
+		Build("corr-reoffer")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	resp := &TicketResponse{Content: TicketResponseContent{
+		TicketID: "reoffer-001",
+		Status:   "rejected",
+		BetDetails: []BetDetail{
+			{Code: -701, AlternativeStake: &AlternativeStake{Stake: 50000}}, // 5.00
+			{Code: -701, AlternativeStake: &AlternativeStake{Stake: 30000}}, // 3.00 over 4 lines
+			{},
+		},
+	}}
+	expires := time.Now().Add(time.Minute)
+	reoffer, err := NewReoffer(ticket, resp, expires)
+	if err != nil || reoffer == nil {
+		t.Fatalf("Expected a reoffer, got %v, %v", reoffer, err)
+	}
+	if len(reoffer.Bets) != 2 || reoffer.Bets[1].Index != 1 {
+		t.Fatalf("Unexpected offered bets %+v", reoffer.Bets)
+	}
+	if s := reoffer.Bets[0].Stake; s[0].Amount != "3" || s[1].Amount != "2" {
+		t.Errorf("Expected cash 3 and free 2, got %+v", s)
+	}
+	if s := reoffer.Bets[1].Stake; s[0].Amount != "0.75" || s[0].Mode != "unit" {
+		t.Errorf("Expected 0.75 per line, got %+v", s)
+	}
+
+	accepted := reoffer.Accept("corr-accept")
+	if accepted.Content.TicketID != "reoffer-001-reoffer" || accepted.CorrelationID != "corr-accept" {
+		t.Errorf("Unexpected accepted ticket %s / %s", accepted.Content.TicketID, accepted.CorrelationID)
+	}
+	if len(accepted.Content.Bets) != 2 || accepted.Content.Bets[1].Stake[0].Amount != "0.75" {
+		t.Errorf("Expected only the offered bets at their offered stakes, got %+v", accepted.Content.Bets)
+	}
+	if accepted.Content.Bets[1].Index != 1 {
+		t.Errorf("Expected the trixie to keep its request index, got %d", accepted.Content.Bets[1].Index)
+	}
+	if ticket.Content.TicketID != "reoffer-001" || ticket.Content.Bets[0].Stake[0].Amount != "6" {
+		t.Error("Accept must not modify the original ticket")
+	}
+
+	if !reoffer.Expired(expires) || reoffer.Expired(expires.Add(-time.Second)) {
+		t.Error("Expected the offer to expire exactly at ExpiresAt")
+	}
+}
+
+func TestNoReofferWithoutAlternativeStake(t *testing.T) {
+	ticket, _ := NewTicketBuilder(45426, "reoffer-002").
+		AddSingleBet(NewSelection("3", "sr:match:1", "1", "1", "2.00"), NewStake("cash", "EUR", "1", "total")).
+		Build("corr")
+
+	for _, resp := range []*TicketResponse{
+		{Content: TicketResponseContent{Status: "rejected", BetDetails: []BetDetail{{Code: -405}}}},
+		{Content: TicketResponseContent{Status: "accepted", BetDetails: []BetDetail{{AlternativeStake: &AlternativeStake{Stake: 1}}}}},
+	} {
+		if reoffer, err := NewReoffer(ticket, resp, time.Now()); reoffer != nil || err != nil {
+			t.Errorf("Expected no reoffer for %+v, got %+v, %v", resp.Content, reoffer, err)
+		}
+	}
+}
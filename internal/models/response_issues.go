@@ -0,0 +1,229 @@
+package models
+
+import "fmt"
+
+// Issue severities
+const (
+	SeverityError   = "error"   // The ticket or bet was rejected
+	SeverityWarning = "warning" // Accepted with a remark, or an alternative was offered
+)
+
+// Suggested actions for the end user
+const (
+	ActionRemoveSelection        = "remove_selection"         // The selection cannot be bet on
+	ActionReduceStake            = "reduce_stake"             // A stake or liability limit was hit
+	ActionAcceptAlternativeStake = "accept_alternative_stake" // MTS offered a lower stake
+	ActionRefreshOdds            = "refresh_odds"             // Odds moved; re-confirm at the new price
+	ActionFixRequest             = "fix_request"              // The request itself is invalid
+	ActionContactSupport         = "contact_support"          // Nothing the user can change
+)
+
+// Issue is one problem reported by MTS, flattened from the ticket, bet and
+// selection levels of a TicketResponse
+type Issue struct {
+	Level      string            `json:"level"`              // "ticket", "bet" or "selection"
+	BetIndex   *int              `json:"betIndex,omitempty"` // Index of the bet in the request
+	BetID      string            `json:"betId,omitempty"`
+	Selection  *int              `json:"selection,omitempty"` // Index within the bet, counting inside system selections
+	Field      string            `json:"field,omitempty"`     // Path within the bet, e.g. "selections[0].selections[2]"
+	Code       int               `json:"code"`
+	Severity   string            `json:"severity"`
+	Action     string            `json:"action"`
+	MTSMessage string            `json:"mtsMessage,omitempty"` // Message as sent by MTS
+	Messages   map[string]string `json:"messages"`             // Localised explanation by language, e.g. "en", "zh"
+}
+
+// catalogueEntry explains one MTS code or code family
+type catalogueEntry struct {
+	action   string
+	messages map[string]string
+}
+
+// issueCatalogue holds explanations for individual MTS codes
+var issueCatalogue = map[int]catalogueEntry{
+	-401: {ActionRemoveSelection, map[string]string{
+		"en": "This event is not available for betting.",
+		"zh": "该赛事暂不可投注。",
+	}},
+	-405: {ActionRemoveSelection, map[string]string{
+		"en": "This market is not available for betting.",
+		"zh": "该盘口暂不可投注。",
+	}},
+	-423: {ActionRemoveSelection, map[string]string{
+		"en": "This market has closed or its line has changed.",
+		"zh": "该盘口已关闭或盘口线已变化。",
+	}},
+	-701: {ActionReduceStake, map[string]string{
+		"en": "The stake exceeds the allowed limit. Please lower your stake.",
+		"zh": "投注金额超出限额，请降低投注金额。",
+	}},
+	-1001: {ActionRefreshOdds, map[string]string{
+		"en": "The odds have changed. Please confirm the new odds.",
+		"zh": "赔率已变化，请确认新赔率。",
+	}},
+}
+
+// issueFamilies explains codes without their own entry by hundreds, e.g. -4xx
+var issueFamilies = map[int]catalogueEntry{
+	1: {ActionFixRequest, map[string]string{
+		"en": "The bet request is invalid.",
+		"zh": "投注请求无效。",
+	}},
+	4: {ActionRemoveSelection, map[string]string{
+		"en": "A selection is not available for betting.",
+		"zh": "某个选项暂不可投注。",
+	}},
+	7: {ActionReduceStake, map[string]string{
+		"en": "A betting limit was exceeded. Please lower your stake.",
+		"zh": "超出投注限额，请降低投注金额。",
+	}},
+	10: {ActionRefreshOdds, map[string]string{
+		"en": "The odds have changed. Please confirm the new odds.",
+		"zh": "赔率已变化，请确认新赔率。",
+	}},
+}
+
+var defaultIssue = catalogueEntry{ActionContactSupport, map[string]string{
+	"en": "The bet could not be accepted.",
+	"zh": "投注未被接受。",
+}}
+
+var alternativeStakeIssue = catalogueEntry{ActionAcceptAlternativeStake, map[string]string{
+	"en": "The full stake was not accepted, but a lower stake is available.",
+	"zh": "未接受全部投注金额，但可以接受较低的投注金额。",
+}}
+
+// InterpretResponse flattens the codes of a ticket response into issues that
+// point at the bets and selections of the request. A code repeated
+// unchanged at a lower level is reported only there. Response bets are
+// matched to request bets by bet ID or position and selections by outcome.
+func InterpretResponse(ticket *TicketRequest, resp *TicketResponse) []Issue {
+	if resp == nil {
+		return nil
+	}
+	severity := SeverityError
+	if resp.Content.Status == "accepted" {
+		severity = SeverityWarning
+	}
+
+	var issues []Issue
+	reported := make(map[string]bool)
+	for i, detail := range resp.Content.BetDetails {
+		index, betID, bet := requestBet(ticket, detail, i)
+		inBet := make(map[string]bool)
+		for _, sd := range detail.SelectionDetails {
+			if sd.Code == 0 {
+				continue
+			}
+			issue := newIssue("selection", sd.Code, sd.Message, severity)
+			issue.BetIndex, issue.BetID = index, betID
+			if bet != nil {
+				if j, path, ok := findSelection(bet.Selections, sd.Selection); ok {
+					issue.Selection, issue.Field = &j, selectionField(path)
+				}
+			}
+			issues = append(issues, issue)
+			inBet[issueKey(sd.Code, sd.Message)] = true
+		}
+		if detail.Code != 0 && !inBet[issueKey(detail.Code, detail.Message)] {
+			issue := newIssue("bet", detail.Code, detail.Message, severity)
+			issue.BetIndex, issue.BetID = index, betID
+			issues = append(issues, issue)
+		}
+		for key := range inBet {
+			reported[key] = true
+		}
+		reported[issueKey(detail.Code, detail.Message)] = true
+		if detail.AlternativeStake != nil {
+			issue := Issue{Level: "bet", BetIndex: index, BetID: betID, Code: detail.Code, Severity: SeverityWarning}
+			applyEntry(&issue, alternativeStakeIssue)
+			issues = append(issues, issue)
+		}
+	}
+
+	if resp.Content.Code != 0 && !reported[issueKey(resp.Content.Code, resp.Content.Message)] {
+		issues = append(issues, newIssue("ticket", resp.Content.Code, resp.Content.Message, severity))
+	}
+	return issues
+}
+
+func newIssue(level string, code int, message, severity string) Issue {
+	issue := Issue{Level: level, Code: code, Severity: severity, MTSMessage: message}
+	applyEntry(&issue, lookupIssue(code))
+	return issue
+}
+
+func applyEntry(issue *Issue, entry catalogueEntry) {
+	issue.Action = entry.action
+	issue.Messages = make(map[string]string, len(entry.messages))
+	for lang, text := range entry.messages {
+		issue.Messages[lang] = text
+	}
+}
+
+// lookupIssue finds the catalogue entry for a code, then its family
+func lookupIssue(code int) catalogueEntry {
+	if entry, ok := issueCatalogue[code]; ok {
+		return entry
+	}
+	if entry, ok := issueFamilies[-code/100]; ok && code < 0 {
+		return entry
+	}
+	return defaultIssue
+}
+
+func issueKey(code int, message string) string {
+	return fmt.Sprintf("%d|%s", code, message)
+}
+
+// requestBet returns the request index, ID and bet a response bet refers to,
+// matched by bet ID or else by position
+func requestBet(ticket *TicketRequest, detail BetDetail, position int) (*int, string, *Bet) {
+	if i, ok := ticket.BetPosition(detail, position); ok {
+		bet := &ticket.Content.Bets[i]
+		betID := detail.BetID
+		if betID == "" {
+			betID = bet.BetID
+		}
+		return &bet.Index, betID, bet
+	}
+	return &position, detail.BetID, nil
+}
+
+// findSelection locates a selection among a bet's selections, looking inside
+// system selections, and returns its running index and index path
+func findSelection(selections []Selection, target Selection) (int, []int, bool) {
+	key := resultKey(target)
+	index := 0
+	var walk func(prefix []int, sels []Selection) ([]int, bool)
+	walk = func(prefix []int, sels []Selection) ([]int, bool) {
+		for i, sel := range sels {
+			path := append(append([]int(nil), prefix...), i)
+			if sel.Type == SelectionTypeSystem {
+				if found, ok := walk(path, sel.Selections); ok {
+					return found, true
+				}
+				continue
+			}
+			if resultKey(sel) == key {
+				return path, true
+			}
+			index++
+		}
+		return nil, false
+	}
+	path, ok := walk(nil, selections)
+	return index, path, ok
+}
+
+// selectionField renders an index path, e.g. "selections[0].selections[2]"
+func selectionField(path []int) string {
+	field := ""
+	for i, index := range path {
+		if i > 0 {
+			field += "."
+		}
+		field += fmt.Sprintf("selections[%d]", index)
+	}
+	return field
+}
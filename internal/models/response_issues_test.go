@@ -0,0 +1,86 @@
+package models
+
+import "testing"
+
+func TestInterpretRejectedSystemBet(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+	}
+	banker := NewSelection("3", "sr:match:4", "1", "1", "1.50")
+	ticket, err := NewTicketBuilder(45426, "issues-001").
+		AddSingleBet(NewSelection("3", "sr:match:5", "1", "1", "3.00"), NewStake("cash", "EUR", "1", "total")).
+		AddBankerSystemBet([]Selection{banker}, []int{2}, sels, NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-issues")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	message := "Market is not found in MTS (selection: uof:3/sr:match:3/1/1)"
+	resp := &TicketResponse{Content: TicketResponseContent{
+		Status:  "rejected",
+		Code:    -405,
+		Message: message,
+		BetDetails: []BetDetail{
+			{},
+			{Code: -405, Message: message, SelectionDetails: []SelectionDetail{
+				{Selection: banker},
+				{Selection: sels[2], Code: -405, Message: message},
+			}},
+		},
+	}}
+
+	issues := InterpretResponse(ticket, resp)
+	if len(issues) != 1 {
+		t.Fatalf("Expected the repeated code to collapse into 1 issue, got %+v", issues)
+	}
+	issue := issues[0]
+	if issue.Level != "selection" || *issue.BetIndex != 1 || *issue.Selection != 2 || issue.Field != "selections[0].selections[2]" {
+		t.Errorf("Unexpected location %+v", issue)
+	}
+	if issue.Severity != SeverityError || issue.Action != ActionRemoveSelection || issue.MTSMessage != message {
+		t.Errorf("Unexpected issue %+v", issue)
+	}
+	if issue.Messages["en"] == "" || issue.Messages["zh"] == "" {
+		t.Errorf("Expected EN and ZH messages, got %v", issue.Messages)
+	}
+}
+
+func TestInterpretLevelsAndFallbacks(t *testing.T) {
+	resp := &TicketResponse{Content: TicketResponseContent{
+		Status:  "rejected",
+		Code:    -101,
+		Message: "Invalid ticket",
+		BetDetails: []BetDetail{
+			{Code: -750, Message: "Bet limit", AlternativeStake: &AlternativeStake{Stake: 5000}},
+		},
+	}}
+
+	issues := InterpretResponse(nil, resp)
+	expected := []struct {
+		level, action string
+	}{
+		{"bet", ActionReduceStake},
+		{"bet", ActionAcceptAlternativeStake},
+		{"ticket", ActionFixRequest},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), issues)
+	}
+	for i, want := range expected {
+		if issues[i].Level != want.level || issues[i].Action != want.action {
+			t.Errorf("Issue %d = %s/%s, expected %s/%s", i, issues[i].Level, issues[i].Action, want.level, want.action)
+		}
+	}
+	if issues[1].Severity != SeverityWarning {
+		t.Errorf("Expected the alternative stake to be a warning, got %s", issues[1].Severity)
+	}
+
+	if got := lookupIssue(-9999); got.action != ActionContactSupport {
+		t.Errorf("Expected unknown codes to fall back to contact support, got %s", got.action)
+	}
+	if issues := InterpretResponse(nil, &TicketResponse{Content: TicketResponseContent{Status: "accepted"}}); len(issues) != 0 {
+		t.Errorf("Expected no issues for a clean acceptance, got %+v", issues)
+	}
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SelectionVariant is a Selection typed by its Type: a UFSelection,
+// ExternalSelection, CustomBetSelection or SystemSelection. Each variant holds
+// only the fields valid for its type and marshals to the same JSON as the
+// Selection it came from.
+type SelectionVariant interface {
+	SelectionType() string
+	Selection() Selection
+	Accept(v SelectionVisitor) error
+	json.Marshaler
+}
+
+// SelectionVisitor handles every selection variant. Implementations must
+// cover all of them, so adding a variant breaks the build rather than
+// silently skipping selections.
+type SelectionVisitor interface {
+	VisitUF(sel UFSelection) error
+	VisitExternal(sel ExternalSelection) error
+	VisitCustomBet(sel CustomBetSelection) error
+	VisitSystem(sel SystemSelection) error
+}
+
+// UFSelection is an outcome of a Unified Odds Feed market
+type UFSelection struct {
+	ProductID  string
+	EventID    string
+	MarketID   string
+	OutcomeID  string
+	Specifiers string
+	Odds       *Odds
+}
+
+// ExternalSelection is an outcome of a non-UOF event with free-form IDs
+type ExternalSelection struct {
+	EventID    string
+	MarketID   string
+	OutcomeID  string
+	Specifiers string
+	Odds       *Odds
+}
+
+// CustomBetSelection combines outcomes of one event at a single price
+type CustomBetSelection struct {
+	ProductID string
+	EventID   string
+	Odds      *Odds
+	Legs      []UFSelection // Unpriced; EventID may be left empty
+}
+
+// SystemSelection combines its selections in every combination of Size
+type SystemSelection struct {
+	Size       []int
+	Selections []SelectionVariant // Never a SystemSelection
+}
+
+func (UFSelection) SelectionType() string        { return SelectionTypeUF }
+func (ExternalSelection) SelectionType() string  { return SelectionTypeExternal }
+func (CustomBetSelection) SelectionType() string { return SelectionTypeCustomBet }
+func (SystemSelection) SelectionType() string    { return SelectionTypeSystem }
+
+func (s UFSelection) Accept(v SelectionVisitor) error        { return v.VisitUF(s) }
+func (s ExternalSelection) Accept(v SelectionVisitor) error  { return v.VisitExternal(s) }
+func (s CustomBetSelection) Accept(v SelectionVisitor) error { return v.VisitCustomBet(s) }
+func (s SystemSelection) Accept(v SelectionVisitor) error    { return v.VisitSystem(s) }
+
+// Selection returns the wire form of the selection
+func (s UFSelection) Selection() Selection {
+	return Selection{
+		Type:       SelectionTypeUF,
+		ProductID:  s.ProductID,
+		EventID:    s.EventID,
+		MarketID:   s.MarketID,
+		OutcomeID:  s.OutcomeID,
+		Specifiers: s.Specifiers,
+		Odds:       s.Odds,
+	}
+}
+
+// Selection returns the wire form of the selection
+func (s ExternalSelection) Selection() Selection {
+	return Selection{
+		Type:       SelectionTypeExternal,
+		EventID:    s.EventID,
+		MarketID:   s.MarketID,
+		OutcomeID:  s.OutcomeID,
+		Specifiers: s.Specifiers,
+		Odds:       s.Odds,
+	}
+}
+
+// Selection returns the wire form of the selection
+func (s CustomBetSelection) Selection() Selection {
+	sel := Selection{
+		Type:      SelectionTypeCustomBet,
+		ProductID: s.ProductID,
+		EventID:   s.EventID,
+		Odds:      s.Odds,
+	}
+	for _, leg := range s.Legs {
+		sel.Selections = append(sel.Selections, leg.Selection())
+	}
+	return sel
+}
+
+// Selection returns the wire form of the selection
+func (s SystemSelection) Selection() Selection {
+	sel := Selection{Type: SelectionTypeSystem, Size: s.Size}
+	for _, child := range s.Selections {
+		sel.Selections = append(sel.Selections, child.Selection())
+	}
+	return sel
+}
+
+func (s UFSelection) MarshalJSON() ([]byte, error)        { return json.Marshal(s.Selection()) }
+func (s ExternalSelection) MarshalJSON() ([]byte, error)  { return json.Marshal(s.Selection()) }
+func (s CustomBetSelection) MarshalJSON() ([]byte, error) { return json.Marshal(s.Selection()) }
+func (s SystemSelection) MarshalJSON() ([]byte, error)    { return json.Marshal(s.Selection()) }
+
+func (s *UFSelection) UnmarshalJSON(data []byte) error        { return unmarshalVariant(data, s) }
+func (s *ExternalSelection) UnmarshalJSON(data []byte) error  { return unmarshalVariant(data, s) }
+func (s *CustomBetSelection) UnmarshalJSON(data []byte) error { return unmarshalVariant(data, s) }
+func (s *SystemSelection) UnmarshalJSON(data []byte) error    { return unmarshalVariant(data, s) }
+
+// unmarshalVariant decodes a selection and stores it in target, which must
+// point to the variant its type names
+func unmarshalVariant(data []byte, target interface{}) error {
+	variant, err := UnmarshalSelection(data)
+	if err != nil {
+		return err
+	}
+	switch t := target.(type) {
+	case *UFSelection:
+		if v, ok := variant.(UFSelection); ok {
+			*t = v
+			return nil
+		}
+	case *ExternalSelection:
+		if v, ok := variant.(ExternalSelection); ok {
+			*t = v
+			return nil
+		}
+	case *CustomBetSelection:
+		if v, ok := variant.(CustomBetSelection); ok {
+			*t = v
+			return nil
+		}
+	case *SystemSelection:
+		if v, ok := variant.(SystemSelection); ok {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("selection of type %q cannot be decoded as %T", variant.SelectionType(), target)
+}
+
+// UnmarshalSelection decodes a selection of any type into its variant
+func UnmarshalSelection(data []byte) (SelectionVariant, error) {
+	var sel Selection
+	if err := json.Unmarshal(data, &sel); err != nil {
+		return nil, err
+	}
+	return sel.Typed()
+}
+
+// Typed returns the selection as a variant holding only its type's fields.
+// Fields that do not belong to the type, an unknown type, custom bet legs
+// that are not uf and nested system selections are errors.
+func (s Selection) Typed() (SelectionVariant, error) {
+	switch s.Type {
+	case SelectionTypeUF, SelectionTypeExternal:
+		if len(s.Size) > 0 || len(s.Selections) > 0 {
+			return nil, fmt.Errorf("%s selection cannot have size or nested selections", s.Type)
+		}
+		if s.Type == SelectionTypeExternal {
+			if s.ProductID != "" {
+				return nil, fmt.Errorf("external selection cannot have a productId")
+			}
+			return ExternalSelection{EventID: s.EventID, MarketID: s.MarketID, OutcomeID: s.OutcomeID, Specifiers: s.Specifiers, Odds: s.Odds}, nil
+		}
+		return UFSelection{ProductID: s.ProductID, EventID: s.EventID, MarketID: s.MarketID, OutcomeID: s.OutcomeID, Specifiers: s.Specifiers, Odds: s.Odds}, nil
+
+	case SelectionTypeCustomBet:
+		if len(s.Size) > 0 || s.MarketID != "" || s.OutcomeID != "" || s.Specifiers != "" {
+			return nil, fmt.Errorf("custom bet selection cannot have size, marketId, outcomeId or specifiers; they belong to its legs")
+		}
+		custom := CustomBetSelection{ProductID: s.ProductID, EventID: s.EventID, Odds: s.Odds}
+		for i, leg := range s.Selections {
+			typed, err := leg.Typed()
+			if err != nil {
+				return nil, fmt.Errorf("selections[%d]: %w", i, err)
+			}
+			uf, ok := typed.(UFSelection)
+			if !ok {
+				return nil, fmt.Errorf("selections[%d]: custom bet legs must be of type %q", i, SelectionTypeUF)
+			}
+			custom.Legs = append(custom.Legs, uf)
+		}
+		return custom, nil
+
+	case SelectionTypeSystem:
+		if s.ProductID != "" || s.EventID != "" || s.MarketID != "" || s.OutcomeID != "" || s.Specifiers != "" || s.Odds != nil {
+			return nil, fmt.Errorf("system selection can only have size and selections")
+		}
+		system := SystemSelection{Size: s.Size}
+		for i, child := range s.Selections {
+			typed, err := child.Typed()
+			if err != nil {
+				return nil, fmt.Errorf("selections[%d]: %w", i, err)
+			}
+			if _, nested := typed.(SystemSelection); nested {
+				return nil, fmt.Errorf("selections[%d]: system selections cannot be nested", i)
+			}
+			system.Selections = append(system.Selections, typed)
+		}
+		return system, nil
+	}
+	return nil, fmt.Errorf("unknown selection type %q", s.Type)
+}
+
+// Typed returns the variant of the selection MTS echoed
+func (d SelectionDetail) Typed() (SelectionVariant, error) {
+	return d.Selection.Typed()
+}
+
+// WalkSelections types each selection and visits it; the selections of a
+// system selection are visited right after it. Custom bet legs are part of
+// their custom bet and not visited on their own. The first error stops the walk.
+func WalkSelections(selections []Selection, v SelectionVisitor) error {
+	for i, sel := range selections {
+		typed, err := sel.Typed()
+		if err != nil {
+			return fmt.Errorf("selections[%d]: %w", i, err)
+		}
+		if err := walkVariant(typed, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkVariant(sel SelectionVariant, v SelectionVisitor) error {
+	if err := sel.Accept(v); err != nil {
+		return err
+	}
+	if system, ok := sel.(SystemSelection); ok {
+		for _, child := range system.Selections {
+			if err := walkVariant(child, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// countingVisitor counts the selections of each type
+type countingVisitor map[string]int
+
+func (c countingVisitor) VisitUF(UFSelection) error { c[SelectionTypeUF]++; return nil }
+func (c countingVisitor) VisitExternal(ExternalSelection) error {
+	c[SelectionTypeExternal]++
+	return nil
+}
+func (c countingVisitor) VisitCustomBet(sel CustomBetSelection) error {
+	c[SelectionTypeCustomBet] += len(sel.Legs)
+	return nil
+}
+func (c countingVisitor) VisitSystem(SystemSelection) error { c[SelectionTypeSystem]++; return nil }
+
+func TestSelectionVariantsRoundTrip(t *testing.T) {
+	custom := NewCustomBetSelection("3", "sr:match:3", "3.10",
+		Selection{Type: SelectionTypeUF, MarketID: "1", OutcomeID: "1"},
+		Selection{Type: SelectionTypeUF, MarketID: "18", OutcomeID: "12", Specifiers: "total=2.5"})
+	ticket, err := NewTicketBuilder(45426, "variants-001").
+		AddBankerSystemBet(
+			[]Selection{NewSelection("3", "sr:match:1", "1", "1", "1.50")},
+			[]int{2},
+			[]Selection{NewExternalSelection("ext:1", "win", "a", "2.00"), custom, NewSelection("3", "sr:match:4", "18", "12", "1.90", "total=2.5")},
+			NewStake("cash", "EUR", "1", "unit")).
+		Build("corr-variants-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	bet := ticket.Content.Bets[0]
+	for i, sel := range bet.Selections {
+		want, _ := json.Marshal(sel)
+		typed, err := UnmarshalSelection(want)
+		if err != nil {
+			t.Fatalf("Selection %d: %v", i, err)
+		}
+		got, _ := json.Marshal(typed)
+		if string(got) != string(want) {
+			t.Errorf("Selection %d did not round-trip:\n got %s\nwant %s", i, got, want)
+		}
+	}
+
+	var system SystemSelection
+	data, _ := json.Marshal(bet.Selections[0])
+	if err := json.Unmarshal(data, &system); err != nil || len(system.Selections) != 3 {
+		t.Fatalf("Expected a system of 3 selections, got %+v, %v", system, err)
+	}
+	var uf UFSelection
+	if err := json.Unmarshal(data, &uf); err == nil {
+		t.Error("Expected a system selection not to decode as uf")
+	}
+
+	counts := countingVisitor{}
+	if err := WalkSelections(bet.Selections, counts); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	expected := map[string]int{SelectionTypeUF: 2, SelectionTypeExternal: 1, SelectionTypeCustomBet: 2, SelectionTypeSystem: 1}
+	for kind, n := range expected {
+		if counts[kind] != n {
+			t.Errorf("Expected %d %s, got %d", n, kind, counts[kind])
+		}
+	}
+}
+
+func TestSelectionTypedRejectsMismatchedFields(t *testing.T) {
+	invalid := []Selection{
+		{Type: "outright"},
+		{Type: SelectionTypeUF, Size: []int{2}},
+		{Type: SelectionTypeSystem, EventID: "sr:match:1"},
+		{Type: SelectionTypeSystem, Selections: []Selection{{Type: SelectionTypeSystem}}},
+		{Type: SelectionTypeCustomBet, Selections: []Selection{{Type: SelectionTypeExternal}}},
+	}
+	for _, sel := range invalid {
+		if _, err := sel.Typed(); err == nil {
+			t.Errorf("Expected %+v to be rejected", sel)
+		}
+	}
+}
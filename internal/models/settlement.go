@@ -0,0 +1,246 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// Selection result types accepted by SettleTicket, as in CurrentResult.Type.
+// Half-win and half-lose are shorthands for win and lose with a void factor of 0.5.
+const (
+	ResultUnsettled = "unsettled"
+	ResultWin       = "win"
+	ResultLose      = "lose"
+	ResultVoid      = "void"
+	ResultHalfWin   = "half-win"
+	ResultHalfLose  = "half-lose"
+)
+
+// Line statuses reported by SettleTicket
+const (
+	LineWon     = "won"     // Pays more than a refund
+	LineLost    = "lost"    // Pays nothing
+	LineVoid    = "void"    // Every selection was void; the stake is refunded
+	LinePartial = "partial" // Pays back part of the stake, e.g. a half-lose or a dead heat
+	LinePending = "pending" // A selection is unsettled and none has lost
+)
+
+// SelectionResult is the result of one selection, shaped like a
+// CashoutSelectionDetail. Custom bets are settled as one selection.
+type SelectionResult struct {
+	Selection Selection     `json:"selection"`
+	Result    CurrentResult `json:"currentResult"`
+}
+
+// Settlement is the locally calculated payout of a ticket
+type Settlement struct {
+	TicketID    string          `json:"ticketId"`
+	Currency    string          `json:"currency"`
+	TotalStake  string          `json:"totalStake"`
+	TotalReturn string          `json:"totalReturn"` // Sum of settled line returns
+	Settled     bool            `json:"settled"`     // No line is pending
+	Bets        []BetSettlement `json:"bets"`
+}
+
+// BetSettlement is the payout of one bet of a ticket
+type BetSettlement struct {
+	Index        int           `json:"index"`
+	Part         string        `json:"part,omitempty"` // "win" or "place" for each-way bets
+	Stake        string        `json:"stake"`
+	Return       string        `json:"return"`
+	PendingLines int           `json:"pendingLines"`
+	Lines        []SettledLine `json:"lines"`
+}
+
+// SettledLine is the payout of one line of a bet
+type SettledLine struct {
+	Index      int         `json:"index"`
+	Selections []Selection `json:"selections"`
+	Odds       string      `json:"odds"` // Combined decimal odds as placed
+	Stake      string      `json:"stake"`
+	Factor     string      `json:"factor"` // Return per unit staked after results, e.g. the odds for a plain win
+	Return     string      `json:"return"`
+	Status     string      `json:"status"`
+}
+
+// SettleTicket calculates the return of every line of every bet of a ticket
+// from per-selection results. A winning selection pays its odds, reduced by
+// its dead-heat factor; a void selection counts as odds 1; a void factor
+// refunds that share of the stake whatever the result. Line returns are
+// rounded down to MTS precision. Free-bet stake is never paid back, only the
+// winnings above it. Selections without a result are treated as unsettled.
+func SettleTicket(ticket *TicketRequest, results []SelectionResult) (*Settlement, error) {
+	byKey := make(map[string]CurrentResult, len(results))
+	for i, r := range results {
+		if _, err := selectionFactor(r.Selection, r.Result); err != nil {
+			return nil, fmt.Errorf("results[%d]: %w", i, err)
+		}
+		byKey[resultKey(r.Selection)] = r.Result
+	}
+
+	settlement := &Settlement{TicketID: ticket.Content.TicketID, Settled: true}
+	totalStake, totalReturn := decimal.Zero, decimal.Zero
+	for i, bet := range ticket.Content.Bets {
+		bs, stake, ret, err := settleBet(bet, byKey)
+		if err != nil {
+			return nil, fmt.Errorf("bet[%d]: %w", i, err)
+		}
+		if currency := bet.Stake[0].Currency; settlement.Currency == "" {
+			settlement.Currency = currency
+		} else if currency != settlement.Currency {
+			return nil, fmt.Errorf("bet[%d]: currency %s differs from %s", i, currency, settlement.Currency)
+		}
+		bs.Index = i
+		settlement.Bets = append(settlement.Bets, *bs)
+		settlement.Settled = settlement.Settled && bs.PendingLines == 0
+		totalStake = totalStake.Add(stake)
+		totalReturn = totalReturn.Add(ret)
+	}
+	settlement.TotalStake = totalStake.StringMTS()
+	settlement.TotalReturn = totalReturn.StringMTS()
+	return settlement, nil
+}
+
+func settleBet(bet Bet, results map[string]CurrentResult) (bs *BetSettlement, totalStake, totalReturn decimal.Decimal, err error) {
+	lines, err := ExpandBet(bet)
+	if err != nil {
+		return nil, decimal.Zero, decimal.Zero, err
+	}
+	stake, free, err := lineStake(bet.Stake, int64(len(lines)))
+	if err != nil {
+		return nil, decimal.Zero, decimal.Zero, err
+	}
+
+	bs = &BetSettlement{Part: bet.Part, Lines: make([]SettledLine, len(lines))}
+	totalStake, totalReturn = decimal.Zero, decimal.Zero
+	for i, line := range lines {
+		settled := SettledLine{
+			Index:      line.Index,
+			Selections: line.Selections,
+			Odds:       line.Odds,
+			Stake:      line.Stake,
+		}
+		factor, status, lineErr := lineFactor(line.Selections, results)
+		if lineErr != nil {
+			return nil, decimal.Zero, decimal.Zero, fmt.Errorf("line[%d]: %w", i, lineErr)
+		}
+		settled.Status = status
+		if status == LinePending {
+			bs.PendingLines++
+		} else {
+			// Free-bet stake only pays the winnings above it
+			ret := stake.Mul(factor).Sub(decimal.Min(free, free.Mul(factor)))
+			ret = ret.Round(decimal.MTSScale, decimal.RoundDown)
+			settled.Factor = factor.Normalize().String()
+			settled.Return = ret.StringMTS()
+			totalReturn = totalReturn.Add(ret)
+		}
+		totalStake = totalStake.Add(stake)
+		bs.Lines[i] = settled
+	}
+	bs.Stake = totalStake.StringMTS()
+	bs.Return = totalReturn.StringMTS()
+	return bs, totalStake, totalReturn, nil
+}
+
+// lineFactor multiplies the factors of a line's selections. A lost selection
+// settles the line even while others are unsettled.
+func lineFactor(selections []Selection, results map[string]CurrentResult) (decimal.Decimal, string, error) {
+	factor := decimal.One
+	pending, allVoid := false, true
+	for _, sel := range selections {
+		result, ok := results[resultKey(sel)]
+		if !ok || result.Type == ResultUnsettled || result.Type == "" {
+			pending = true
+			continue
+		}
+		f, err := selectionFactor(sel, result)
+		if err != nil {
+			return decimal.Zero, "", err
+		}
+		if f.IsZero() {
+			return decimal.Zero, LineLost, nil
+		}
+		allVoid = allVoid && result.Type == ResultVoid
+		factor = factor.Mul(f)
+	}
+
+	switch {
+	case pending:
+		return decimal.Zero, LinePending, nil
+	case allVoid:
+		return factor, LineVoid, nil
+	case factor.LessThan(decimal.One):
+		return factor, LinePartial, nil
+	}
+	return factor, LineWon, nil
+}
+
+// selectionFactor is the return per unit staked on one settled selection:
+// (1 - voidFactor) × deadHeatFactor × odds + voidFactor for a win,
+// voidFactor for a loss and 1 for a void
+func selectionFactor(sel Selection, result CurrentResult) (decimal.Decimal, error) {
+	resultType := result.Type
+	voidFactor, err := parseFactor("voidFactor", result.VoidFactor, decimal.Zero, true)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	switch resultType {
+	case ResultHalfWin, ResultHalfLose:
+		if result.VoidFactor != "" {
+			return decimal.Zero, fmt.Errorf("%s cannot be combined with a voidFactor", resultType)
+		}
+		voidFactor = decimal.New(5, 1)
+		resultType = strings.TrimPrefix(resultType, "half-")
+	}
+
+	switch resultType {
+	case ResultUnsettled, "":
+		return decimal.One, nil
+	case ResultVoid:
+		return decimal.One, nil
+	case ResultLose:
+		return voidFactor, nil
+	case ResultWin:
+		deadHeat, err := parseFactor("deadHeatFactor", result.DeadHeatFactor, decimal.One, false)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if sel.Odds == nil {
+			return decimal.Zero, fmt.Errorf("odds is required to settle a win")
+		}
+		odds, err := decimal.Parse(sel.Odds.Value)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid odds %q", sel.Odds.Value)
+		}
+		won := decimal.One.Sub(voidFactor).Mul(deadHeat).Mul(odds)
+		return won.Add(voidFactor), nil
+	}
+	return decimal.Zero, fmt.Errorf("unknown result type %q", result.Type)
+}
+
+// parseFactor reads a factor between 0 and 1; 0 itself is only allowed when
+// allowZero is set. An empty value yields def.
+func parseFactor(name, value string, def decimal.Decimal, allowZero bool) (decimal.Decimal, error) {
+	if value == "" {
+		return def, nil
+	}
+	f, err := decimal.Parse(value)
+	if err != nil || f.Sign() < 0 || (f.IsZero() && !allowZero) || f.GreaterThan(decimal.One) {
+		return decimal.Zero, fmt.Errorf("%s %q must be a number between 0 and 1", name, value)
+	}
+	return f, nil
+}
+
+// resultKey identifies the outcome a selection backs, independent of odds
+func resultKey(sel Selection) string {
+	k := keyOf(sel)
+	key := eventKey(sel) + "|" + k.market + "|" + k.specifiers + "|" + k.outcome
+	for _, leg := range sel.Selections {
+		k = keyOf(leg)
+		key += "|" + k.market + "/" + k.specifiers + "/" + k.outcome
+	}
+	return key
+}
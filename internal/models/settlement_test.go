@@ -0,0 +1,137 @@
+package models
+
+import "testing"
+
+func settleBuilt(t *testing.T, builder *TicketBuilder, results ...SelectionResult) *Settlement {
+	t.Helper()
+	ticket, err := builder.Build("corr-settle")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	settlement, err := SettleTicket(ticket, results)
+	if err != nil {
+		t.Fatalf("Failed to settle ticket: %v", err)
+	}
+	return settlement
+}
+
+func settledAs(sel Selection, resultType string) SelectionResult {
+	return SelectionResult{Selection: sel, Result: CurrentResult{Type: resultType}}
+}
+
+func TestSettleSingleAndAccumulator(t *testing.T) {
+	single := NewSelection("3", "sr:match:1", "1", "1", "2.50")
+	legs := []Selection{
+		NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "1.80"),
+		NewSelection("3", "sr:match:4", "16", "1714", "3.00", "hcp=-0.75"),
+	}
+	builder := NewTicketBuilder(45426, "settle-001").
+		AddSingleBet(single, NewStake("cash", "EUR", "10", "total")).
+		AddAccumulatorBet(legs, NewStake("cash", "EUR", "10", "total"))
+
+	// 2.00 × void × half-win (0.5 × 3.00 + 0.5 = 2) = 4
+	settlement := settleBuilt(t, builder,
+		settledAs(single, ResultWin),
+		settledAs(legs[0], ResultWin),
+		settledAs(legs[1], ResultVoid),
+		settledAs(legs[2], ResultHalfWin),
+	)
+	if !settlement.Settled || settlement.TotalStake != "20" || settlement.TotalReturn != "65" {
+		t.Errorf("Expected settled stake 20 returning 65, got %+v", settlement)
+	}
+	if line := settlement.Bets[0].Lines[0]; line.Return != "25" || line.Factor != "2.5" || line.Status != LineWon {
+		t.Errorf("Unexpected single line %+v", line)
+	}
+	if line := settlement.Bets[1].Lines[0]; line.Return != "40" || line.Factor != "4" || line.Status != LineWon {
+		t.Errorf("Unexpected accumulator line %+v", line)
+	}
+}
+
+func TestSettleTrixieWithLoser(t *testing.T) {
+	sels := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2"),
+		NewSelection("3", "sr:match:2", "1", "1", "3"),
+		NewSelection("3", "sr:match:3", "1", "1", "4"),
+	}
+	builder := NewTicketBuilder(45426, "settle-002").AddTrixieBet(sels, NewStake("cash", "EUR", "1", "unit"))
+
+	settlement := settleBuilt(t, builder,
+		settledAs(sels[0], ResultWin),
+		settledAs(sels[1], ResultWin),
+		settledAs(sels[2], ResultLose),
+	)
+	bet := settlement.Bets[0]
+	if bet.Stake != "4" || bet.Return != "6" {
+		t.Errorf("Expected stake 4 returning 6, got %s and %s", bet.Stake, bet.Return)
+	}
+	statuses := []string{LineWon, LineLost, LineLost, LineLost}
+	for i, line := range bet.Lines {
+		if line.Status != statuses[i] {
+			t.Errorf("Line %d status = %s, expected %s", i, line.Status, statuses[i])
+		}
+	}
+}
+
+func TestSettleDeadHeatHalfLoseAndFreeStake(t *testing.T) {
+	horse := NewSelection("3", "sr:stage:1", "40", "2", "5.00")
+	asian := NewSelection("3", "sr:match:2", "16", "1714", "1.90", "hcp=-0.25")
+	free := NewSelection("3", "sr:match:3", "1", "1", "3.00")
+	builder := NewTicketBuilder(45426, "settle-003").
+		AddSingleBet(horse, NewStake("cash", "EUR", "2", "total")).
+		AddSingleBet(asian, NewStake("cash", "EUR", "10", "total")).
+		AddSingleBet(free, NewStake("cash", "EUR", "6", "total"), NewStake("free", "EUR", "4", "total"))
+
+	settlement := settleBuilt(t, builder,
+		SelectionResult{Selection: horse, Result: CurrentResult{Type: ResultWin, DeadHeatFactor: "0.5"}},
+		settledAs(asian, ResultHalfLose),
+		settledAs(free, ResultWin),
+	)
+
+	expected := []struct{ ret, status string }{
+		{"5", LineWon},     // 2 × 0.5 × 5.00
+		{"5", LinePartial}, // half the stake refunded
+		{"26", LineWon},    // 10 × 3.00 less the 4 free-bet stake
+	}
+	for i, want := range expected {
+		line := settlement.Bets[i].Lines[0]
+		if line.Return != want.ret || line.Status != want.status {
+			t.Errorf("Bet %d line = %s (%s), expected %s (%s)", i, line.Return, line.Status, want.ret, want.status)
+		}
+	}
+}
+
+func TestSettlePendingLines(t *testing.T) {
+	legs := []Selection{
+		NewSelection("3", "sr:match:1", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:2", "1", "1", "2.00"),
+		NewSelection("3", "sr:match:3", "1", "1", "2.00"),
+	}
+	builder := NewTicketBuilder(45426, "settle-004").
+		AddAccumulatorBet(legs[:2], NewStake("cash", "EUR", "1", "total")).
+		AddAccumulatorBet([]Selection{legs[1], legs[2]}, NewStake("cash", "EUR", "1", "total"))
+
+	settlement := settleBuilt(t, builder, settledAs(legs[0], ResultWin), settledAs(legs[2], ResultLose))
+	if settlement.Settled || settlement.Bets[0].PendingLines != 1 || settlement.Bets[0].Lines[0].Return != "" {
+		t.Errorf("Expected the first accumulator to be pending, got %+v", settlement.Bets[0])
+	}
+	if line := settlement.Bets[1].Lines[0]; line.Status != LineLost || line.Return != "0" {
+		t.Errorf("Expected a loser to settle the line while others are pending, got %+v", line)
+	}
+}
+
+func TestSettleRejectsInvalidResults(t *testing.T) {
+	sel := NewSelection("3", "sr:match:1", "1", "1", "2.00")
+	ticket, _ := NewTicketBuilder(45426, "settle-005").AddSingleBet(sel, NewStake("cash", "EUR", "1", "total")).Build("corr")
+
+	for _, r := range []CurrentResult{
+		{Type: "push"},
+		{Type: ResultWin, DeadHeatFactor: "0"},
+		{Type: ResultLose, VoidFactor: "1.5"},
+		{Type: ResultHalfWin, VoidFactor: "0.5"},
+	} {
+		if _, err := SettleTicket(ticket, []SelectionResult{{Selection: sel, Result: r}}); err == nil {
+			t.Errorf("Expected error for result %+v", r)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// SizeStake is the unit stake of one combination size of a system bet
+type SizeStake struct {
+	Size   int     `json:"size"`
+	Stakes []Stake `json:"stake"`
+}
+
+// StakeSplit reports a total stake converted into a unit stake per line
+type StakeSplit struct {
+	BetIndex  int    `json:"betIndex"` // Index of the bet in the request
+	Type      string `json:"type"`     // Stake type, e.g. "cash"
+	Currency  string `json:"currency"`
+	Total     string `json:"total"` // Total stake requested
+	Lines     int64  `json:"lines"`
+	Unit      string `json:"unit"`      // Unit stake placed on each line
+	Remainder string `json:"remainder"` // Part of the total left unplaced by rounding down
+}
+
+// SetSizeStakes gives each combination size of the previous Add call's
+// system, banker or preset bet its own unit stake, e.g. 2 EUR on doubles and
+// 1 EUR on trebles. Every size becomes a separate bet under the same bet
+// index and every size needs a stake; the stakes of the Add call are
+// replaced. Errors are reported on "sizeStakes[i]".
+func (tb *TicketBuilder) SetSizeStakes(stakes []SizeStake) *TicketBuilder {
+	index := tb.betCount - 1
+	if index < 0 {
+		tb.fail(-1, "sizeStakes", "size stakes must follow a bet")
+		return tb
+	}
+	if len(stakes) == 0 {
+		tb.fail(index, "sizeStakes", "at least one size stake is required")
+		return tb
+	}
+	bySize := make(map[int][]Stake, len(stakes))
+	for i, s := range stakes {
+		field := fmt.Sprintf("sizeStakes[%d]", i)
+		if _, dup := bySize[s.Size]; dup {
+			tb.fail(index, field+".size", fmt.Sprintf("size %d is repeated", s.Size))
+		}
+		tb.checkStakesAt(index, field+".stake", s.Stakes)
+		if len(s.Stakes) > 0 && s.Stakes[0].Mode != "unit" {
+			tb.fail(index, field+".stake.mode", "size stakes must be unit stakes")
+		}
+		bySize[s.Size] = s.Stakes
+	}
+	if tb.lastBet < 0 {
+		// The bet was rejected; its errors are already recorded
+		return tb
+	}
+
+	var bets []Bet
+	used := make(map[int]bool, len(stakes))
+	for _, bet := range tb.bets[tb.lastBet:] {
+		for _, size := range betSizes(bet) {
+			s, ok := bySize[size]
+			if !ok {
+				tb.fail(index, "sizeStakes", fmt.Sprintf("size %d has no stake", size))
+				continue
+			}
+			used[size] = true
+			bets = append(bets, withSize(bet, size, s))
+		}
+	}
+	for i, s := range stakes {
+		if !used[s.Size] {
+			tb.fail(index, fmt.Sprintf("sizeStakes[%d].size", i), fmt.Sprintf("the bet has no combinations of size %d", s.Size))
+		}
+	}
+
+	tb.bets = tb.bets[:tb.lastBet]
+	if tb.hasErrors(index) {
+		tb.lastBet = -1
+		return tb
+	}
+	tb.bets = append(tb.bets, bets...)
+	return tb
+}
+
+// betSizes returns the combination sizes of a bet; an accumulator is one
+// combination of all its selections
+func betSizes(bet Bet) []int {
+	for _, sel := range bet.Selections {
+		if sel.Type == SelectionTypeSystem {
+			return sel.Size
+		}
+	}
+	return []int{len(bet.Selections)}
+}
+
+// withSize returns a copy of the bet limited to one combination size
+func withSize(bet Bet, size int, stakes []Stake) Bet {
+	selections := make([]Selection, len(bet.Selections))
+	for i, sel := range bet.Selections {
+		if sel.Type == SelectionTypeSystem {
+			sel.Size = []int{size}
+		}
+		selections[i] = sel
+	}
+	bet.Selections = selections
+	bet.Stake = append([]Stake{}, stakes...)
+	return bet
+}
+
+// SplitTotalStake turns the total stake of the previous Add call into a unit
+// stake, spread over every line of all its bets and rounded down to decimals
+// places, the currency's smallest unit. Unit stakes are left alone. What
+// rounding leaves unplaced is reported in the bets' Splits.
+func (tb *TicketBuilder) SplitTotalStake(decimals int) *TicketBuilder {
+	index := tb.betCount - 1
+	if index < 0 {
+		tb.fail(-1, "stake", "a stake split must follow a bet")
+		return tb
+	}
+	if tb.lastBet < 0 {
+		return tb
+	}
+	bets := tb.bets[tb.lastBet:]
+	stakes := bets[0].Stake
+	if len(stakes) == 0 || stakes[0].Mode != "total" {
+		return tb
+	}
+
+	var lines int64
+	for _, bet := range bets {
+		n, err := CountLines(bet)
+		if err != nil {
+			tb.fail(index, "stake", err.Error())
+			return tb
+		}
+		lines += n
+	}
+
+	units := make([]Stake, len(stakes))
+	splits := make([]StakeSplit, len(stakes))
+	for k, stake := range stakes {
+		field := "stake"
+		if len(stakes) > 1 {
+			field = fmt.Sprintf("stake[%d]", k)
+		}
+		total, _ := decimal.Parse(stake.Amount) // Checked by the Add call
+		unit := total.DivInt(lines, decimals, decimal.RoundDown)
+		if unit.Sign() <= 0 {
+			tb.fail(index, field+".amount", fmt.Sprintf("total stake %s is too small to spread over %d lines", stake.Amount, lines))
+			continue
+		}
+		stake.Amount = unit.StringMTS()
+		stake.Mode = "unit"
+		units[k] = stake
+		splits[k] = StakeSplit{
+			BetIndex:  index,
+			Type:      stake.Type,
+			Currency:  stake.Currency,
+			Total:     total.StringMTS(),
+			Lines:     lines,
+			Unit:      unit.StringMTS(),
+			Remainder: total.Sub(unit.MulInt(lines)).StringMTS(),
+		}
+	}
+	if tb.hasErrors(index) {
+		tb.bets = tb.bets[:tb.lastBet]
+		tb.lastBet = -1
+		return tb
+	}
+	for i := range bets {
+		bets[i].Stake = append([]Stake{}, units...)
+		bets[i].Splits = splits
+	}
+	return tb
+}
+
+// StakeSplits returns the total stakes of a ticket that were converted into
+// unit stakes, once per request bet
+func StakeSplits(ticket *TicketRequest) []StakeSplit {
+	if ticket == nil {
+		return nil
+	}
+	var splits []StakeSplit
+	seen := make(map[int]bool)
+	for _, bet := range ticket.Content.Bets {
+		if len(bet.Splits) == 0 || seen[bet.Index] {
+			continue
+		}
+		seen[bet.Index] = true
+		splits = append(splits, bet.Splits...)
+	}
+	return splits
+}
@@ -0,0 +1,79 @@
+package models
+
+import "testing"
+
+func TestSetSizeStakes(t *testing.T) {
+	ticket, err := NewTicketBuilder(45426, "size-stakes-001").
+		AddSystemBet([]int{2, 3}, presetSelections(4), NewStake("cash", "EUR", "2", "unit")).
+		SetSizeStakes([]SizeStake{
+			{Size: 2, Stakes: []Stake{NewStake("cash", "EUR", "2", "unit")}},
+			{Size: 3, Stakes: []Stake{NewStake("cash", "EUR", "1", "unit")}},
+		}).
+		SetBetID("sys").
+		Build("corr-size-stakes-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	bets := ticket.Content.Bets
+	if len(bets) != 2 {
+		t.Fatalf("Expected one bet per size, got %d", len(bets))
+	}
+	for i, expected := range []struct{ notation, amount, id string }{{"2/4", "2", "sys"}, {"3/4", "1", "sys-2"}} {
+		if BetNotation(bets[i]) != expected.notation || bets[i].Stake[0].Amount != expected.amount || bets[i].BetID != expected.id {
+			t.Errorf("Bet %d: expected %+v, got %s %s %s", i, expected, BetNotation(bets[i]), bets[i].Stake[0].Amount, bets[i].BetID)
+		}
+		if bets[i].Index != 0 {
+			t.Errorf("Bet %d: expected request index 0, got %d", i, bets[i].Index)
+		}
+	}
+
+	_, err = NewTicketBuilder(45426, "size-stakes-002").
+		AddYankeeBet(presetSelections(4), NewStake("cash", "EUR", "1", "unit")).
+		SetSizeStakes([]SizeStake{
+			{Size: 2, Stakes: []Stake{NewStake("cash", "EUR", "1", "unit")}},
+			{Size: 5, Stakes: []Stake{NewStake("cash", "EUR", "1", "unit")}},
+		}).
+		Build("corr-size-stakes-002")
+	errs, ok := err.(BuilderErrors)
+	if !ok || len(errs) != 3 {
+		t.Errorf("Expected sizes 3 and 4 without stakes and an unknown size 5, got %v", err)
+	}
+}
+
+func TestSplitTotalStake(t *testing.T) {
+	ticket, err := NewTicketBuilder(45426, "split-001").
+		AddTrixieBet(presetSelections(3), NewStake("cash", "EUR", "10", "total")).
+		SplitTotalStake(2).
+		AddPresetBet("alphabet", presetSelections(6), NewStake("cash", "EUR", "1", "total")).
+		SplitTotalStake(2).
+		Build("corr-split-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	stake := ticket.Content.Bets[0].Stake[0]
+	if stake.Mode != "unit" || stake.Amount != "2.5" {
+		t.Errorf("Expected a unit stake of 2.5, got %+v", stake)
+	}
+	splits := StakeSplits(ticket)
+	if len(splits) != 2 {
+		t.Fatalf("Expected a split per bet, got %+v", splits)
+	}
+	if splits[1].BetIndex != 1 || splits[1].Lines != 26 || splits[1].Unit != "0.03" || splits[1].Remainder != "0.22" {
+		t.Errorf("Unexpected alphabet split %+v", splits[1])
+	}
+	for _, bet := range ticket.Content.Bets[1:] {
+		if bet.Stake[0].Amount != "0.03" {
+			t.Errorf("Expected every alphabet bet to stake 0.03, got %s", bet.Stake[0].Amount)
+		}
+	}
+
+	_, err = NewTicketBuilder(45426, "split-002").
+		AddGoliathBet(presetSelections(8), NewStake("cash", "EUR", "1", "total")).
+		SplitTotalStake(2).
+		Build("corr-split-002")
+	if err == nil {
+		t.Error("Expected 1 EUR to be too small for 247 lines")
+	}
+}
@@ -34,8 +34,38 @@ type Channel struct {
 
 // Bet represents a single bet within a ticket
 type Bet struct {
-	Selections []Selection `json:"selections"` // Array of selections, must contain at least one
-	Stake      []Stake     `json:"stake"`      // Array of stake objects, must contain at least one
+	BetID      string      `json:"betId,omitempty"` // Unique within the ticket; echoed in BetDetail.BetID
+	Selections []Selection `json:"selections"`      // Array of selections, must contain at least one
+	Stake      []Stake     `json:"stake"`           // Array of stake objects, must contain at least one
+	Part       string      `json:"-"`               // BetPartWin or BetPartPlace for each-way bets; not sent to MTS
+	Index      int         `json:"-"`               // Index of the bet in the request; each-way parts share it
+	Legs       []int       `json:"-"`               // Positions in the request's selections of a preset part's selections
+	Splits     []StakeSplit `json:"-"`              // Total stakes converted into this bet's unit stakes
+}
+
+// Bet kinds, as told apart by Bet.Kind
+const (
+	BetKindSingle      = "single"
+	BetKindAccumulator = "accumulator"
+	BetKindSystem      = "system"
+	BetKindBanker      = "banker"
+)
+
+// Kind tells the kind of a bet from its selections: a system selection is a
+// system bet on its own and a banker bet when bankers sit beside it
+func (b Bet) Kind() string {
+	for _, sel := range b.Selections {
+		if sel.Type == SelectionTypeSystem {
+			if len(b.Selections) > 1 {
+				return BetKindBanker
+			}
+			return BetKindSystem
+		}
+	}
+	if len(b.Selections) == 1 {
+		return BetKindSingle
+	}
+	return BetKindAccumulator
 }
 
 // Selection represents a single selection within a bet
@@ -55,9 +85,20 @@ type Selection struct {
 
 	// Fields for system bets (type="system")
 	Size       []int       `json:"size,omitempty"`       // Array of combination sizes (e.g., [2,3] for doubles and trebles)
-	Selections []Selection `json:"selections,omitempty"` // Nested selections for system bets
+	Selections []Selection `json:"selections,omitempty"` // Nested selections for system bets, or legs of a custom bet
 }
 
+// Selection types
+const (
+	SelectionTypeUF        = "uf"            // Unified Odds Feed event, market and outcome
+	SelectionTypeExternal  = "external"      // Non-UOF event with free-form IDs
+	SelectionTypeCustomBet = "uf-custom-bet" // Same-game combination; legs are nested in Selections
+	SelectionTypeSystem    = "system"        // Container for system and banker bets
+)
+
+// MinCustomBetLegs is the minimum number of legs in a custom bet
+const MinCustomBetLegs = 2
+
 // Odds represents the odds for a selection
 type Odds struct {
 	Type  string `json:"type"`  // Odds type (e.g., "decimal")
@@ -66,10 +107,22 @@ type Odds struct {
 
 // Stake represents a stake object within a bet
 type Stake struct {
-	Type     string `json:"type"`     // Stake type (e.g., "cash", "free")
+	Type     string `json:"type"`     // Stake type: "cash", "free" or "bonus"; a bet has at most one entry per type
 	Currency string `json:"currency"` // Currency code (e.g., "EUR", "mBTC")
 	Amount   string `json:"amount"`   // Amount as a string (e.g., "10")
-	Mode     string `json:"mode,omitempty"` // Optional mode (e.g., "total")
+	Mode     string `json:"mode,omitempty"` // "total" or "unit"; required when placing
+}
+
+// Stake types
+const (
+	StakeTypeCash  = "cash"
+	StakeTypeFree  = "free"  // Free bet: only the winnings are paid, the stake is not returned
+	StakeTypeBonus = "bonus" // Bonus money, returned with the winnings like cash
+)
+
+// ReturnsStake reports whether a winning line pays back stake of this type
+func ReturnsStake(stakeType string) bool {
+	return stakeType != StakeTypeFree
 }
 
 // ExchangeRate represents currency exchange rate information
@@ -79,6 +132,23 @@ type ExchangeRate struct {
 	Rate         string `json:"rate"`         // Exchange rate as string (e.g., "1.00000000")
 }
 
+// BetPosition finds the bet of the ticket that a response bet answers: by bet
+// ID when the response carries one, otherwise by its position in the response
+func (t *TicketRequest) BetPosition(detail BetDetail, position int) (int, bool) {
+	if t == nil {
+		return 0, false
+	}
+	if detail.BetID != "" {
+		for i, bet := range t.Content.Bets {
+			if bet.BetID == detail.BetID {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	return position, position < len(t.Content.Bets)
+}
+
 // TicketResponse represents the response from MTS
 type TicketResponse struct {
 	OperatorID    int64                  `json:"operatorId,omitempty"`
@@ -2,15 +2,55 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+	"github.com/gdsZyy/mts-service/internal/uof"
 )
 
-// TicketBuilder helps construct MTS ticket requests
+// TicketBuilder helps construct MTS ticket requests.
+// Invalid input never panics: each Add method records a BuilderError and
+// skips the bet, and Validate/Build report every recorded error at once.
 type TicketBuilder struct {
 	operatorID int64
 	ticketID   string
 	bets       []Bet
 	context    *Context
+	betCount   int            // Number of Add calls, so errors carry the caller's bet index
+	lastBet    int            // Position in bets of the previous Add call's first bet, -1 if it was rejected
+	betIDs     map[int]string // Bet IDs given with SetBetID, by Add call index
+	errs       BuilderErrors
+}
+
+// BuilderError describes one invalid input recorded by TicketBuilder
+type BuilderError struct {
+	BetIndex int    `json:"betIndex"`        // Index of the Add call, -1 for ticket-level errors
+	Field    string `json:"field,omitempty"` // Field path within the bet (e.g. "selections[1].odds")
+	Code     string `json:"code,omitempty"`  // Machine-readable reason, e.g. a Conflict code
+	Message  string `json:"message"`
+}
+
+func (e *BuilderError) Error() string {
+	prefix := "ticket"
+	if e.BetIndex >= 0 {
+		prefix = fmt.Sprintf("bet[%d]", e.BetIndex)
+	}
+	if e.Field != "" {
+		prefix += "." + e.Field
+	}
+	return prefix + ": " + e.Message
+}
+
+// BuilderErrors is the error returned by Validate and Build
+type BuilderErrors []*BuilderError
+
+func (e BuilderErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
 }
 
 // NewTicketBuilder creates a new ticket builder
@@ -19,6 +59,7 @@ func NewTicketBuilder(operatorID int64, ticketID string) *TicketBuilder {
 		operatorID: operatorID,
 		ticketID:   ticketID,
 		bets:       []Bet{},
+		lastBet:    -1,
 	}
 }
 
@@ -28,59 +69,60 @@ func (tb *TicketBuilder) SetContext(ctx *Context) *TicketBuilder {
 	return tb
 }
 
-
-
 // AddSingleBet adds a single bet to the ticket
-func (tb *TicketBuilder) AddSingleBet(selection Selection, stake Stake) *TicketBuilder {
-	bet := Bet{
+func (tb *TicketBuilder) AddSingleBet(selection Selection, stakes ...Stake) *TicketBuilder {
+	bet := tb.beginBet()
+	tb.checkSelection(bet, "selection", selection)
+	tb.checkConflicts(bet, false, []Leg{{Field: "selection", Selection: selection}})
+	tb.checkStakes(bet, stakes)
+	return tb.appendBet(bet, Bet{
 		Selections: []Selection{selection},
-		Stake:      []Stake{stake},
-	}
-	tb.bets = append(tb.bets, bet)
-	return tb
+		Stake:      stakes,
+	})
 }
 
 // AddAccumulatorBet adds an accumulator bet (multiple selections, all must win)
-func (tb *TicketBuilder) AddAccumulatorBet(selections []Selection, stake Stake) *TicketBuilder {
+func (tb *TicketBuilder) AddAccumulatorBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	bet := tb.beginBet()
 	if len(selections) < 2 {
-		panic("accumulator requires at least 2 selections")
+		tb.fail(bet, "selections", "accumulator requires at least 2 selections")
 	}
-	bet := Bet{
+	tb.checkSelections(bet, "selections", selections)
+	tb.checkConflicts(bet, true, LabelLegs("selections", selections))
+	tb.checkStakes(bet, stakes)
+	return tb.appendBet(bet, Bet{
 		Selections: selections,
-		Stake:      []Stake{stake},
-	}
-	tb.bets = append(tb.bets, bet)
-	return tb
+		Stake:      stakes,
+	})
 }
 
 // AddSystemBet adds a system bet (e.g., 2/3, 3/5)
 // size: array of combination sizes (e.g., [2] for doubles, [2,3] for doubles and trebles)
 // selections: the selections to combine
-// stake: unit stake (mode should be "unit" for system bets)
-func (tb *TicketBuilder) AddSystemBet(size []int, selections []Selection, stake Stake) *TicketBuilder {
+// stakes: unit stake entries (mode should be "unit" for system bets)
+func (tb *TicketBuilder) AddSystemBet(size []int, selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.addSystemBet(tb.beginBet(), size, selections, stakes)
+}
+
+func (tb *TicketBuilder) addSystemBet(bet int, size []int, selections []Selection, stakes []Stake) *TicketBuilder {
 	if len(selections) < 2 {
-		panic("system bet requires at least 2 selections")
-	}
-	
-	// Validate size values
-	for _, s := range size {
-		if s < 1 || s > len(selections) {
-			panic(fmt.Sprintf("invalid size %d for %d selections", s, len(selections)))
-		}
+		tb.fail(bet, "selections", "system bet requires at least 2 selections")
 	}
-	
+	tb.checkSizes(bet, size, len(selections), "selections")
+	tb.checkSelections(bet, "selections", selections)
+	tb.checkConflicts(bet, combinesSelections(size), LabelLegs("selections", selections))
+	tb.checkStakes(bet, stakes)
+
 	systemSelection := Selection{
 		Type:       "system",
 		Size:       size,
 		Selections: selections,
 	}
-	
-	bet := Bet{
+
+	return tb.appendBet(bet, Bet{
 		Selections: []Selection{systemSelection},
-		Stake:      []Stake{stake},
-	}
-	tb.bets = append(tb.bets, bet)
-	return tb
+		Stake:      stakes,
+	})
 }
 
 // AddBankerSystemBet adds a system bet with banker selections
@@ -88,198 +130,561 @@ func (tb *TicketBuilder) AddSystemBet(size []int, selections []Selection, stake
 // - One selection of type "system" containing the non-banker selections
 // - One or more selections of type "uf" (the banker selections)
 // All selections are at the same level in the selections array.
-// 
+//
 // Example: 3/4 system with 1 banker = 2/3 system + 1 banker selection
 // The bet will have 2 top-level selections:
-//   1. system selection with 3 nested non-banker selections and size=[2]
-//   2. uf selection (the banker)
-// 
+//  1. system selection with 3 nested non-banker selections and size=[2]
+//  2. uf selection (the banker)
+//
 // bankers: selections that must be in every combination
 // size: array of combination sizes for non-banker selections
 // selections: non-banker selections to combine
-// stake: unit stake
-func (tb *TicketBuilder) AddBankerSystemBet(bankers []Selection, size []int, selections []Selection, stake Stake) *TicketBuilder {
+// stakes: unit stake entries
+func (tb *TicketBuilder) AddBankerSystemBet(bankers []Selection, size []int, selections []Selection, stakes ...Stake) *TicketBuilder {
+	bet := tb.beginBet()
 	if len(bankers) < 1 {
-		panic("banker system bet requires at least 1 banker")
+		tb.fail(bet, "bankers", "banker system bet requires at least 1 banker")
 	}
 	if len(selections) < 2 {
-		panic("banker system bet requires at least 2 non-banker selections")
-	}
-	
-	// Validate size values
-	for _, s := range size {
-		if s < 1 || s > len(selections) {
-			panic(fmt.Sprintf("invalid size %d for %d non-banker selections", s, len(selections)))
-		}
+		tb.fail(bet, "selections", "banker system bet requires at least 2 non-banker selections")
 	}
-	
+	tb.checkSizes(bet, size, len(selections), "non-banker selections")
+	tb.checkSelections(bet, "bankers", bankers)
+	tb.checkSelections(bet, "selections", selections)
+	tb.checkConflicts(bet, true, append(LabelLegs("bankers", bankers), LabelLegs("selections", selections)...))
+	tb.checkStakes(bet, stakes)
+
 	// Create system selection for non-banker selections
 	systemSelection := Selection{
 		Type:       "system",
 		Size:       size,
 		Selections: selections,
 	}
-	
+
 	// Build top-level selections array: system selection + banker selections
 	topLevelSelections := []Selection{systemSelection}
 	topLevelSelections = append(topLevelSelections, bankers...)
-	
-	bet := Bet{
+
+	return tb.appendBet(bet, Bet{
 		Selections: topLevelSelections,
-		Stake:      []Stake{stake},
+		Stake:      stakes,
+	})
+}
+
+// AddPresetBet adds a preset from the registry by name or alias, e.g.
+// "yankee" or "full_cover"; see Presets. A preset made of several bets adds
+// each of them under the one bet index, with the same stakes.
+func (tb *TicketBuilder) AddPresetBet(name string, selections []Selection, stakes ...Stake) *TicketBuilder {
+	bet := tb.beginBet()
+	preset, ok := LookupPreset(name)
+	if !ok {
+		tb.fail(bet, "type", fmt.Sprintf("unknown preset type: %s", name))
+		return tb
 	}
-	tb.bets = append(tb.bets, bet)
-	return tb
+	layout, err := preset.Layout(len(selections))
+	if err != nil {
+		tb.fail(bet, "selections", err.Error())
+		return tb
+	}
+	if len(preset.Parts) == 0 {
+		return tb.addSystemBet(bet, layout[0].Sizes, selections, stakes)
+	}
+
+	// Stricter than needed for presets such as the Union Jack, where not
+	// every pair of selections shares a bet
+	tb.checkSelections(bet, "selections", selections)
+	tb.checkConflicts(bet, true, LabelLegs("selections", selections))
+	tb.checkStakes(bet, stakes)
+
+	bets := make([]Bet, len(layout))
+	for i, part := range layout {
+		picked := make([]Selection, len(part.Legs))
+		for j, leg := range part.Legs {
+			picked[j] = selections[leg]
+		}
+		bets[i] = Bet{Stake: append([]Stake{}, stakes...), Legs: part.Legs}
+		if len(part.Sizes) == 1 && part.Sizes[0] == len(picked) {
+			bets[i].Selections = picked
+		} else {
+			bets[i].Selections = []Selection{{Type: SelectionTypeSystem, Size: part.Sizes, Selections: picked}}
+		}
+	}
+	return tb.appendBet(bet, bets...)
 }
 
 // AddTrixieBet adds a Trixie bet (3 selections: 3 doubles + 1 treble = 4 bets)
-func (tb *TicketBuilder) AddTrixieBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 3 {
-		panic("trixie requires exactly 3 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3}, selections, stake)
+func (tb *TicketBuilder) AddTrixieBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("trixie", selections, stakes...)
 }
 
 // AddPatentBet adds a Patent bet (3 selections: 3 singles + 3 doubles + 1 treble = 7 bets)
-func (tb *TicketBuilder) AddPatentBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 3 {
-		panic("patent requires exactly 3 selections")
-	}
-	return tb.AddSystemBet([]int{1, 2, 3}, selections, stake)
+func (tb *TicketBuilder) AddPatentBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("patent", selections, stakes...)
 }
 
 // AddYankeeBet adds a Yankee bet (4 selections: 6 doubles + 4 trebles + 1 four-fold = 11 bets)
-func (tb *TicketBuilder) AddYankeeBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 4 {
-		panic("yankee requires exactly 4 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3, 4}, selections, stake)
+func (tb *TicketBuilder) AddYankeeBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("yankee", selections, stakes...)
 }
 
 // AddLucky15Bet adds a Lucky 15 bet (4 selections: 4 singles + 6 doubles + 4 trebles + 1 four-fold = 15 bets)
-func (tb *TicketBuilder) AddLucky15Bet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 4 {
-		panic("lucky 15 requires exactly 4 selections")
-	}
-	return tb.AddSystemBet([]int{1, 2, 3, 4}, selections, stake)
+func (tb *TicketBuilder) AddLucky15Bet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("lucky15", selections, stakes...)
 }
 
 // AddSuperYankeeBet adds a Super Yankee/Canadian bet (5 selections: 10 doubles + 10 trebles + 5 four-folds + 1 five-fold = 26 bets)
-func (tb *TicketBuilder) AddSuperYankeeBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 5 {
-		panic("super yankee requires exactly 5 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3, 4, 5}, selections, stake)
+func (tb *TicketBuilder) AddSuperYankeeBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("super_yankee", selections, stakes...)
 }
 
 // AddLucky31Bet adds a Lucky 31 bet (5 selections: 5 singles + 10 doubles + 10 trebles + 5 four-folds + 1 five-fold = 31 bets)
-func (tb *TicketBuilder) AddLucky31Bet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 5 {
-		panic("lucky 31 requires exactly 5 selections")
-	}
-	return tb.AddSystemBet([]int{1, 2, 3, 4, 5}, selections, stake)
+func (tb *TicketBuilder) AddLucky31Bet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("lucky31", selections, stakes...)
 }
 
 // AddHeinzBet adds a Heinz bet (6 selections: 15 doubles + 20 trebles + 15 four-folds + 6 five-folds + 1 six-fold = 57 bets)
-func (tb *TicketBuilder) AddHeinzBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 6 {
-		panic("heinz requires exactly 6 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3, 4, 5, 6}, selections, stake)
+func (tb *TicketBuilder) AddHeinzBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("heinz", selections, stakes...)
 }
 
 // AddLucky63Bet adds a Lucky 63 bet (6 selections: 6 singles + 15 doubles + 20 trebles + 15 four-folds + 6 five-folds + 1 six-fold = 63 bets)
-func (tb *TicketBuilder) AddLucky63Bet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 6 {
-		panic("lucky 63 requires exactly 6 selections")
-	}
-	return tb.AddSystemBet([]int{1, 2, 3, 4, 5, 6}, selections, stake)
+func (tb *TicketBuilder) AddLucky63Bet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("lucky63", selections, stakes...)
 }
 
 // AddSuperHeinzBet adds a Super Heinz bet (7 selections: 21 doubles + 35 trebles + 35 four-folds + 21 five-folds + 7 six-folds + 1 seven-fold = 120 bets)
-func (tb *TicketBuilder) AddSuperHeinzBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 7 {
-		panic("super heinz requires exactly 7 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3, 4, 5, 6, 7}, selections, stake)
+func (tb *TicketBuilder) AddSuperHeinzBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("super_heinz", selections, stakes...)
 }
 
 // AddGoliathBet adds a Goliath bet (8 selections: 28 doubles + 56 trebles + 70 four-folds + 56 five-folds + 28 six-folds + 8 seven-folds + 1 eight-fold = 247 bets)
-func (tb *TicketBuilder) AddGoliathBet(selections []Selection, stake Stake) *TicketBuilder {
-	if len(selections) != 8 {
-		panic("goliath requires exactly 8 selections")
-	}
-	return tb.AddSystemBet([]int{2, 3, 4, 5, 6, 7, 8}, selections, stake)
-}
-
-// Build constructs the final TicketRequest
-func (tb *TicketBuilder) Build(correlationID string) *TicketRequest {
-	if len(tb.bets) == 0 {
-		panic("ticket must contain at least one bet")
-	}
-	
-		return &TicketRequest{
-			OperatorID:    tb.operatorID,
-			CorrelationID: correlationID,
-			TimestampUTC:  time.Now().UnixMilli(),
-			Operation:     "ticket-placement",
-			Version:       "3.0",
-			Content: TicketContent{
-				Type:     "ticket",
-				TicketID: tb.ticketID,
-				Bets:     tb.bets,
-				Context:  tb.context,
-			},
+func (tb *TicketBuilder) AddGoliathBet(selections []Selection, stakes ...Stake) *TicketBuilder {
+	return tb.AddPresetBet("goliath", selections, stakes...)
+}
+
+// SetBetID sets the ID of the bet added by the previous Add call. Bets without
+// an ID get "<ticketId>-<n>" from Build, n being the bet's position in the
+// ticket. Further bets of a preset made of several bets get "-2", "-3" and so
+// on appended, and the place part of an each-way bet gets "-place".
+func (tb *TicketBuilder) SetBetID(id string) *TicketBuilder {
+	index := tb.betCount - 1
+	if index < 0 {
+		tb.fail(-1, "betId", "bet ID must follow a bet")
+		return tb
+	}
+	if id == "" || len(id) > maxBetIDLength-betIDSuffixRoom {
+		tb.fail(index, "betId", fmt.Sprintf("betId must have 1 to %d characters", maxBetIDLength-betIDSuffixRoom))
+		return tb
+	}
+	if tb.betIDs == nil {
+		tb.betIDs = make(map[int]string)
+	}
+	tb.betIDs[index] = id
+	return tb
+}
+
+// maxBetIDLength is the longest bet ID MTS accepts
+const maxBetIDLength = 128
+
+// betIDSuffixRoom is kept free in a given bet ID for the suffixes Build
+// appends, such as "-12-place"
+const betIDSuffixRoom = len("-99-place")
+
+// Validate returns every error recorded so far plus ticket-level problems, or nil
+func (tb *TicketBuilder) Validate() error {
+	errs := append(BuilderErrors{}, tb.errs...)
+	if tb.ticketID == "" {
+		errs = append(errs, &BuilderError{BetIndex: -1, Field: "ticketId", Message: "ticketId is required"})
+	}
+	if tb.betCount == 0 {
+		errs = append(errs, &BuilderError{BetIndex: -1, Field: "bets", Message: "ticket must contain at least one bet"})
+	}
+	// Checked on the IDs sent to MTS, suffixes included
+	seen := make(map[string]int, len(tb.bets))
+	reported := make(map[int]bool)
+	for _, bet := range tb.betsWithIDs() {
+		if reported[bet.Index] {
+			continue
+		}
+		if len(bet.BetID) > maxBetIDLength {
+			errs = append(errs, &BuilderError{BetIndex: bet.Index, Field: "betId", Message: fmt.Sprintf("betId %q is longer than %d characters", bet.BetID, maxBetIDLength)})
+			reported[bet.Index] = true
+		} else if first, dup := seen[bet.BetID]; dup {
+			errs = append(errs, &BuilderError{BetIndex: bet.Index, Field: "betId", Message: fmt.Sprintf("betId %q is already used by bet %d", bet.BetID, first)})
+			reported[bet.Index] = true
+		} else {
+			seen[bet.BetID] = bet.Index
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Build constructs the final TicketRequest, or returns BuilderErrors if any input was invalid
+func (tb *TicketBuilder) Build(correlationID string) (*TicketRequest, error) {
+	if err := tb.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &TicketRequest{
+		OperatorID:    tb.operatorID,
+		CorrelationID: correlationID,
+		TimestampUTC:  time.Now().UnixMilli(),
+		Operation:     "ticket-placement",
+		Version:       "3.0",
+		Content: TicketContent{
+			Type:     "ticket",
+			TicketID: tb.ticketID,
+			Bets:     tb.betsWithIDs(),
+			Context:  tb.context,
+		},
+	}, nil
+}
+
+// betsWithIDs returns a copy of the bets with their IDs filled in. Generated
+// IDs avoid every ID derived from a given one.
+func (tb *TicketBuilder) betsWithIDs() []Bet {
+	type part struct {
+		index int
+		place bool
+	}
+	seen := make(map[part]int)
+	taken := make(map[string]bool, len(tb.bets))
+	bets := make([]Bet, len(tb.bets))
+	for i, bet := range tb.bets {
+		if id, ok := tb.betIDs[bet.Index]; ok {
+			key := part{bet.Index, bet.Part == BetPartPlace}
+			bet.BetID = id
+			if seen[key] > 0 {
+				bet.BetID += fmt.Sprintf("-%d", seen[key]+1)
+			}
+			if key.place {
+				bet.BetID += "-place"
+			}
+			seen[key]++
+			taken[bet.BetID] = true
+		}
+		bets[i] = bet
+	}
+	for i := range bets {
+		if _, ok := tb.betIDs[bets[i].Index]; ok {
+			continue
+		}
+		id := fmt.Sprintf("%s-%d", tb.ticketID, i)
+		for taken[id] {
+			id += "-" + fmt.Sprint(i)
+		}
+		bets[i].BetID = id
+		taken[id] = true
+	}
+	return bets
+}
+
+// beginBet reserves the index of the bet being added
+func (tb *TicketBuilder) beginBet() int {
+	index := tb.betCount
+	tb.betCount++
+	tb.lastBet = -1
+	return index
+}
+
+// appendBet adds the bets of an Add call only if no error was recorded for it
+func (tb *TicketBuilder) appendBet(index int, bets ...Bet) *TicketBuilder {
+	if tb.hasErrors(index) {
+		return tb
+	}
+	tb.lastBet = len(tb.bets)
+	for _, bet := range bets {
+		bet.Index = index
+		tb.bets = append(tb.bets, bet)
+	}
+	return tb
+}
+
+// hasErrors reports whether an error was recorded for the bet index
+func (tb *TicketBuilder) hasErrors(index int) bool {
+	for _, err := range tb.errs {
+		if err.BetIndex == index {
+			return true
+		}
+	}
+	return false
+}
+
+func (tb *TicketBuilder) fail(index int, field, message string) {
+	tb.errs = append(tb.errs, &BuilderError{BetIndex: index, Field: field, Message: message})
+}
+
+// checkConflicts records every correlated leg of the bet; see FindConflicts
+func (tb *TicketBuilder) checkConflicts(index int, combined bool, legs []Leg) {
+	for _, c := range FindConflicts(legs, combined) {
+		tb.errs = append(tb.errs, &BuilderError{BetIndex: index, Field: c.Field, Code: c.Code, Message: c.Message})
+	}
+}
+
+// combinesSelections reports whether any line of a system of these sizes
+// holds more than one selection
+func combinesSelections(size []int) bool {
+	for _, s := range size {
+		if s > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (tb *TicketBuilder) checkSizes(index int, size []int, count int, what string) {
+	if len(size) == 0 {
+		tb.fail(index, "size", "size is required")
+	}
+	seen := make(map[int]bool)
+	for i, s := range size {
+		field := fmt.Sprintf("size[%d]", i)
+		if s < 1 || s > count {
+			tb.fail(index, field, fmt.Sprintf("invalid size %d for %d %s", s, count, what))
+		}
+		if seen[s] {
+			tb.fail(index, field, fmt.Sprintf("duplicate size %d", s))
+		}
+		seen[s] = true
+	}
+}
+
+func (tb *TicketBuilder) checkSelections(index int, field string, selections []Selection) {
+	for i, sel := range selections {
+		tb.checkSelection(index, fmt.Sprintf("%s[%d]", field, i), sel)
+	}
+}
+
+func (tb *TicketBuilder) checkSelection(index int, field string, sel Selection) {
+	switch sel.Type {
+	case SelectionTypeSystem:
+		tb.fail(index, field+".type", "nested system selections must be built with AddSystemBet or AddBankerSystemBet")
+		return
+	case SelectionTypeUF, SelectionTypeExternal:
+		if sel.EventID == "" {
+			tb.fail(index, field+".eventId", "eventId is required")
+		}
+		if sel.MarketID == "" {
+			tb.fail(index, field+".marketId", "marketId is required")
+		}
+		if sel.OutcomeID == "" {
+			tb.fail(index, field+".outcomeId", "outcomeId is required")
+		}
+		if sel.Type == SelectionTypeUF {
+			tb.checkUOFIDs(index, field, sel)
+		}
+	case SelectionTypeCustomBet:
+		tb.checkCustomBet(index, field, sel)
+	default:
+		tb.fail(index, field+".type", fmt.Sprintf("unknown selection type %q", sel.Type))
+		return
+	}
+	if sel.Odds == nil || sel.Odds.Value == "" {
+		tb.fail(index, field+".odds", "odds is required")
+	} else if odds, err := decimal.Parse(sel.Odds.Value); err != nil || odds.Sign() <= 0 {
+		tb.fail(index, field+".odds", fmt.Sprintf("odds %q must be a valid number greater than 0", sel.Odds.Value))
+	}
+}
+
+// checkCustomBet validates a same-game combination: one event, at least two
+// legs, each leg a market outcome of that event without its own odds
+func (tb *TicketBuilder) checkCustomBet(index int, field string, sel Selection) {
+	if sel.EventID == "" {
+		tb.fail(index, field+".eventId", "eventId is required")
+	} else if _, err := uof.ParseURN(sel.EventID); err != nil {
+		tb.fail(index, field+".eventId", err.Error())
+	}
+	if sel.MarketID != "" || sel.OutcomeID != "" {
+		tb.fail(index, field, "marketId and outcomeId belong on the custom bet legs")
+	}
+	if len(sel.Selections) < MinCustomBetLegs {
+		tb.fail(index, field+".selections", fmt.Sprintf("custom bet requires at least %d legs", MinCustomBetLegs))
+	}
+	for i, leg := range sel.Selections {
+		legField := fmt.Sprintf("%s.selections[%d]", field, i)
+		if leg.Type != SelectionTypeUF {
+			tb.fail(index, legField+".type", fmt.Sprintf("custom bet legs must be of type %q", SelectionTypeUF))
+		}
+		if leg.EventID != "" && uof.CanonicalURN(leg.EventID) != uof.CanonicalURN(sel.EventID) {
+			tb.fail(index, legField+".eventId", "custom bet legs must belong to the custom bet event")
+		}
+		if leg.MarketID == "" {
+			tb.fail(index, legField+".marketId", "marketId is required")
+		}
+		if leg.OutcomeID == "" {
+			tb.fail(index, legField+".outcomeId", "outcomeId is required")
+		}
+		tb.checkUOFIDs(index, legField, leg)
+		if leg.Odds != nil {
+			tb.fail(index, legField+".odds", "custom bet legs are priced by the custom bet odds")
+		}
+		if len(leg.Selections) > 0 || len(leg.Size) > 0 {
+			tb.fail(index, legField, "custom bet legs cannot be nested")
+		}
+	}
+}
+
+// checkUOFIDs validates the identifier formats of a uf selection or custom
+// bet leg; missing IDs are reported by the caller
+func (tb *TicketBuilder) checkUOFIDs(index int, field string, sel Selection) {
+	if sel.EventID != "" {
+		if _, err := uof.ParseURN(sel.EventID); err != nil {
+			tb.fail(index, field+".eventId", err.Error())
+		}
+	}
+	if _, err := uof.ParseSpecifiers(sel.Specifiers); err != nil {
+		tb.fail(index, field+".specifiers", err.Error())
+	}
+	if sel.OutcomeID != "" {
+		if err := uof.ValidateOutcomeID(sel.OutcomeID); err != nil {
+			tb.fail(index, field+".outcomeId", err.Error())
 		}
+	}
 }
 
-// NewSelection creates a new standard selection
-// odds can be either float64 or string
+// checkStakes validates the stake entries of a bet: at least one, at most one
+// per stake type, all in one currency and one mode. A single entry is
+// reported as "stake", several as "stake[k]".
+func (tb *TicketBuilder) checkStakes(index int, stakes []Stake) {
+	tb.checkStakesAt(index, "stake", stakes)
+}
+
+// checkStakesAt is checkStakes for stakes given in another field
+func (tb *TicketBuilder) checkStakesAt(index int, name string, stakes []Stake) {
+	if len(stakes) == 0 {
+		tb.fail(index, name, "stake is required")
+		return
+	}
+	if len(stakes) == 1 {
+		tb.checkStake(index, name, stakes[0])
+		return
+	}
+
+	seen := make(map[string]bool, len(stakes))
+	for k, stake := range stakes {
+		field := fmt.Sprintf("%s[%d]", name, k)
+		tb.checkStake(index, field, stake)
+		if seen[stake.Type] {
+			tb.fail(index, field+".type", fmt.Sprintf("only one %q stake is allowed per bet", stake.Type))
+		}
+		seen[stake.Type] = true
+		if stake.Currency != stakes[0].Currency {
+			tb.fail(index, field+".currency", fmt.Sprintf("currency %s differs from %s", stake.Currency, stakes[0].Currency))
+		}
+		if stake.Mode != stakes[0].Mode {
+			tb.fail(index, field+".mode", fmt.Sprintf("mode %q differs from %q", stake.Mode, stakes[0].Mode))
+		}
+	}
+}
+
+func (tb *TicketBuilder) checkStake(index int, field string, stake Stake) {
+	if stake.Type == "" {
+		tb.fail(index, field+".type", "type is required")
+	}
+	if stake.Currency == "" {
+		tb.fail(index, field+".currency", "currency is required")
+	}
+	if stake.Amount == "" {
+		tb.fail(index, field+".amount", "amount is required")
+	} else if amount, err := decimal.Parse(stake.Amount); err != nil || amount.Sign() <= 0 {
+		tb.fail(index, field+".amount", fmt.Sprintf("amount %q must be a valid number greater than 0", stake.Amount))
+	} else if amount.Scale() > decimal.MTSScale {
+		tb.fail(index, field+".amount", fmt.Sprintf("amount %q has more than %d decimal places", stake.Amount, decimal.MTSScale))
+	}
+	if stake.Mode == "" {
+		tb.fail(index, field+".mode", "mode is required")
+	} else if stake.Mode != "total" && stake.Mode != "unit" {
+		tb.fail(index, field+".mode", fmt.Sprintf("mode %q must be \"total\" or \"unit\"", stake.Mode))
+	}
+}
+
+// NewSelection creates a new standard (UOF) selection
+// odds can be a decimal.Decimal, float64 or string; any other type leaves the
+// odds value empty, which TicketBuilder reports as a validation error
 func NewSelection(productID, eventID, marketID, outcomeID string, odds interface{}, specifiers ...string) Selection {
 	spec := ""
 	if len(specifiers) > 0 {
 		spec = specifiers[0]
 	}
-	
-	// Convert odds to string
-	var oddsStr string
-	switch v := odds.(type) {
-	case float64:
-		oddsStr = fmt.Sprintf("%.2f", v)
-	case string:
-		oddsStr = v
-	default:
-		panic(fmt.Sprintf("odds must be float64 or string, got %T", odds))
+
+	return Selection{
+		Type:       SelectionTypeUF,
+		ProductID:  productID,
+		EventID:    eventID,
+		MarketID:   marketID,
+		OutcomeID:  outcomeID,
+		Specifiers: spec,
+		Odds:       newDecimalOdds(odds),
 	}
-	
+}
+
+// NewExternalSelection creates a selection on an event or market that is not
+// part of the Unified Odds Feed; the IDs are free-form operator identifiers
+func NewExternalSelection(eventID, marketID, outcomeID string, odds interface{}, specifiers ...string) Selection {
+	sel := NewSelection("", eventID, marketID, outcomeID, odds, specifiers...)
+	sel.Type = SelectionTypeExternal
+	return sel
+}
+
+// NewCustomBetSelection creates a same-game combination of legs on one event,
+// priced as a whole by odds. Legs are created with NewCustomBetLeg.
+func NewCustomBetSelection(productID, eventID string, odds interface{}, legs ...Selection) Selection {
 	return Selection{
-		Type:       "uf",
+		Type:       SelectionTypeCustomBet,
 		ProductID:  productID,
 		EventID:    eventID,
+		Odds:       newDecimalOdds(odds),
+		Selections: legs,
+	}
+}
+
+// NewCustomBetLeg creates one market outcome of a custom bet
+func NewCustomBetLeg(marketID, outcomeID string, specifiers ...string) Selection {
+	spec := ""
+	if len(specifiers) > 0 {
+		spec = specifiers[0]
+	}
+	return Selection{
+		Type:       SelectionTypeUF,
 		MarketID:   marketID,
 		OutcomeID:  outcomeID,
 		Specifiers: spec,
-		Odds: &Odds{
-			Type:  "decimal",
-			Value: oddsStr,
-		},
 	}
 }
 
+// newDecimalOdds converts a decimal.Decimal, float64 or string to decimal odds
+func newDecimalOdds(odds interface{}) *Odds {
+	var oddsStr string
+	switch v := odds.(type) {
+	case decimal.Decimal:
+		oddsStr = v.String()
+	case float64:
+		oddsStr = decimal.NewFromFloat(v).String()
+	case string:
+		oddsStr = v
+	}
+	return &Odds{Type: "decimal", Value: oddsStr}
+}
+
 // NewStake creates a new stake object
-// amount can be either float64 or string
+// amount can be a decimal.Decimal, float64 or string; decimals and floats are
+// formatted at MTS precision, any other type leaves the amount empty, which
+// TicketBuilder reports as a validation error
 func NewStake(stakeType, currency string, amount interface{}, mode string) Stake {
 	// Convert amount to string
 	var amountStr string
 	switch v := amount.(type) {
+	case decimal.Decimal:
+		amountStr = v.StringMTS()
 	case float64:
-		amountStr = fmt.Sprintf("%.2f", v)
+		amountStr = decimal.NewFromFloat(v).StringMTS()
 	case string:
 		amountStr = v
-	default:
-		panic(fmt.Sprintf("amount must be float64 or string, got %T", amount))
 	}
-	
+
 	return Stake{
 		Type:     stakeType,
 		Currency: currency,
@@ -17,7 +17,10 @@ func TestSingleBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-single-001")
+	ticket, err := builder.Build("corr-single-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	if ticket.Content.Type != "ticket" {
@@ -62,7 +65,10 @@ func TestAccumulatorBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-accumulator-001")
+	ticket, err := builder.Build("corr-accumulator-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	if len(ticket.Content.Bets) != 1 {
@@ -107,7 +113,10 @@ func TestSystemBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-system-001")
+	ticket, err := builder.Build("corr-system-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	if len(ticket.Content.Bets) != 1 {
@@ -157,7 +166,10 @@ func TestTrixieBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-trixie-001")
+	ticket, err := builder.Build("corr-trixie-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	bet := ticket.Content.Bets[0]
@@ -198,7 +210,10 @@ func TestYankeeBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-yankee-001")
+	ticket, err := builder.Build("corr-yankee-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	bet := ticket.Content.Bets[0]
@@ -239,27 +254,30 @@ func TestBankerSystemBet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-banker-001")
+	ticket, err := builder.Build("corr-banker-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	bet := ticket.Content.Bets[0]
 	
-	// Should have 2 selections: 1 banker + 1 system
+	// Should have 2 selections: 1 system + 1 banker
 	if len(bet.Selections) != 2 {
-		t.Fatalf("Expected 2 selections (1 banker + 1 system), got %d", len(bet.Selections))
+		t.Fatalf("Expected 2 selections (1 system + 1 banker), got %d", len(bet.Selections))
 	}
 	
-	// First selection should be banker (standard "uf" type)
-	if bet.Selections[0].Type != "uf" {
-		t.Errorf("Expected first selection type 'uf' (banker), got '%s'", bet.Selections[0].Type)
+	// First selection should be system
+	if bet.Selections[0].Type != "system" {
+		t.Errorf("Expected first selection type 'system', got '%s'", bet.Selections[0].Type)
 	}
 	
-	// Second selection should be system
-	if bet.Selections[1].Type != "system" {
-		t.Errorf("Expected second selection type 'system', got '%s'", bet.Selections[1].Type)
+	// Second selection should be banker (standard "uf" type)
+	if bet.Selections[1].Type != "uf" {
+		t.Errorf("Expected second selection type 'uf' (banker), got '%s'", bet.Selections[1].Type)
 	}
 	
-	systemSel := bet.Selections[1]
+	systemSel := bet.Selections[0]
 	if len(systemSel.Size) != 1 || systemSel.Size[0] != 2 {
 		t.Errorf("Expected size [2], got %v", systemSel.Size)
 	}
@@ -294,7 +312,10 @@ func TestLucky15Bet(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-lucky15-001")
+	ticket, err := builder.Build("corr-lucky15-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	bet := ticket.Content.Bets[0]
@@ -341,7 +362,10 @@ func TestMultipleBetsInOneTicket(t *testing.T) {
 		LimitID: 4268,
 	})
 	
-	ticket := builder.Build("corr-multi-001")
+	ticket, err := builder.Build("corr-multi-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
 	
 	// Validate structure
 	if len(ticket.Content.Bets) != 3 {
@@ -356,3 +380,177 @@ func TestMultipleBetsInOneTicket(t *testing.T) {
 	
 	t.Logf("Multiple Bets in One Ticket JSON:\n%s", string(jsonData))
 }
+
+func TestBuilderAccumulatesErrors(t *testing.T) {
+	builder := NewTicketBuilder(45426, "test-errors-001")
+	
+	// Valid single bet
+	builder.AddSingleBet(NewSelection("3", "sr:match:12345", "1", "1", 2.50), NewStake("cash", "EUR", 5.00, "total"))
+	
+	// Accumulator with a single selection and a missing event
+	builder.AddAccumulatorBet([]Selection{
+		NewSelection("3", "", "1", "1", 1.80),
+	}, NewStake("cash", "EUR", 10.00, "total"))
+	
+	// System bet with an out-of-range size and invalid odds type
+	builder.AddSystemBet([]int{2, 4}, []Selection{
+		NewSelection("3", "sr:match:12346", "1", "2", 2.20),
+		NewSelection("3", "sr:match:12347", "1", "1", 3),
+		NewSelection("3", "sr:match:12348", "1", "2", 1.95),
+	}, NewStake("cash", "EUR", 1.00, "unit"))
+	
+	// Preset with the wrong selection count
+	builder.AddYankeeBet([]Selection{
+		NewSelection("3", "sr:match:12349", "1", "2", 2.20),
+	}, NewStake("cash", "EUR", 1.00, "unit"))
+	
+	// Single without a stake mode
+	builder.AddSingleBet(NewSelection("3", "sr:match:12350", "1", "1", 2.50), NewStake("cash", "EUR", 5.00, ""))
+	
+	ticket, err := builder.Build("corr-errors-001")
+	if err == nil {
+		t.Fatalf("Expected build error, got ticket %+v", ticket)
+	}
+	
+	errs, ok := err.(BuilderErrors)
+	if !ok {
+		t.Fatalf("Expected BuilderErrors, got %T", err)
+	}
+	
+	expected := map[string]bool{
+		"bet[1].selections: accumulator requires at least 2 selections": false,
+		"bet[1].selections[0].eventId: eventId is required":               false,
+		"bet[2].size[1]: invalid size 4 for 3 selections":                 false,
+		"bet[2].selections[1].odds: odds is required":                     false,
+		"bet[3].selections: yankee requires exactly 4 selections, got 1":  false,
+		"bet[4].stake.mode: mode is required":                            false,
+	}
+	for _, e := range errs {
+		if _, ok := expected[e.Error()]; ok {
+			expected[e.Error()] = true
+		}
+		if e.BetIndex == 0 {
+			t.Errorf("Valid bet 0 should not have errors, got %v", e)
+		}
+	}
+	for msg, found := range expected {
+		if !found {
+			t.Errorf("Expected error %q, got %v", msg, err)
+		}
+	}
+}
+
+func TestBuildEmptyTicket(t *testing.T) {
+	_, err := NewTicketBuilder(45426, "").Build("corr-empty-001")
+	if err == nil {
+		t.Fatal("Expected error for ticket without ID and bets")
+	}
+	
+	errs := err.(BuilderErrors)
+	if len(errs) != 2 {
+		t.Errorf("Expected 2 ticket-level errors, got %v", err)
+	}
+	for _, e := range errs {
+		if e.BetIndex != -1 {
+			t.Errorf("Expected ticket-level error, got %v", e)
+		}
+	}
+}
+
+func TestExternalAndCustomBetSelections(t *testing.T) {
+	external := NewExternalSelection("op:event:77", "winner", "home", "1.90")
+	custom := NewCustomBetSelection("3", "sr:match:12345", "4.20",
+		NewCustomBetLeg("1", "1"),
+		NewCustomBetLeg("18", "12", "total=2.5"),
+	)
+
+	ticket, err := NewTicketBuilder(45426, "test-custom-001").
+		AddAccumulatorBet([]Selection{external, custom}, NewStake("cash", "EUR", 5.00, "total")).
+		Build("corr-custom-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+
+	sels := ticket.Content.Bets[0].Selections
+	if sels[0].Type != "external" || sels[0].ProductID != "" {
+		t.Errorf("Expected external selection without product, got %+v", sels[0])
+	}
+	if sels[1].Type != "uf-custom-bet" || len(sels[1].Selections) != 2 || sels[1].Odds.Value != "4.20" {
+		t.Errorf("Unexpected custom bet selection %+v", sels[1])
+	}
+
+	jsonData, _ := json.Marshal(sels[1])
+	expected := `{"type":"uf-custom-bet","productId":"3","eventId":"sr:match:12345","odds":{"type":"decimal","value":"4.20"},` +
+		`"selections":[{"type":"uf","marketId":"1","outcomeId":"1"},{"type":"uf","marketId":"18","outcomeId":"12","specifiers":"total=2.5"}]}`
+	if string(jsonData) != expected {
+		t.Errorf("Custom bet JSON = %s, expected %s", jsonData, expected)
+	}
+}
+
+func TestCustomBetValidation(t *testing.T) {
+	leg := NewCustomBetLeg("1", "1")
+	foreign := NewCustomBetLeg("1", "2")
+	foreign.EventID = "sr:match:99999"
+	priced := NewCustomBetLeg("18", "12", "total=2.5")
+	priced.Odds = &Odds{Type: "decimal", Value: "1.80"}
+
+	_, err := NewTicketBuilder(45426, "test-custom-002").
+		AddSingleBet(NewCustomBetSelection("3", "sr:match:12345", "3.10", leg), NewStake("cash", "EUR", 5.00, "total")).
+		AddSingleBet(NewCustomBetSelection("3", "sr:match:12345", "3.10", foreign, priced), NewStake("cash", "EUR", 5.00, "total")).
+		AddSingleBet(Selection{Type: "bogus"}, NewStake("cash", "EUR", 5.00, "total")).
+		Build("corr-custom-002")
+	if err == nil {
+		t.Fatal("Expected build error")
+	}
+
+	messages := map[string]bool{}
+	for _, e := range err.(BuilderErrors) {
+		messages[e.Error()] = true
+	}
+	for _, msg := range []string{
+		"bet[0].selection.selections: custom bet requires at least 2 legs",
+		"bet[1].selection.selections[0].eventId: custom bet legs must belong to the custom bet event",
+		"bet[1].selection.selections[1].odds: custom bet legs are priced by the custom bet odds",
+		`bet[2].selection.type: unknown selection type "bogus"`,
+	} {
+		if !messages[msg] {
+			t.Errorf("Expected error %q, got %v", msg, err)
+		}
+	}
+}
+
+func TestMultipleStakeEntries(t *testing.T) {
+	selection := NewSelection("3", "sr:match:12345", "1", "1", "2.50")
+
+	ticket, err := NewTicketBuilder(45426, "test-stakes-001").
+		AddSingleBet(selection, NewStake("cash", "EUR", "6", "total"), NewStake("free", "EUR", "4", "total")).
+		Build("corr-stakes-001")
+	if err != nil {
+		t.Fatalf("Failed to build ticket: %v", err)
+	}
+	if stakes := ticket.Content.Bets[0].Stake; len(stakes) != 2 || stakes[1].Type != "free" {
+		t.Errorf("Expected cash and free stake entries, got %+v", stakes)
+	}
+
+	_, err = NewTicketBuilder(45426, "test-stakes-002").
+		AddSingleBet(selection, NewStake("cash", "EUR", "6", "total"), NewStake("cash", "USD", "4", "unit")).
+		AddSingleBet(selection).
+		Build("corr-stakes-002")
+	if err == nil {
+		t.Fatal("Expected build error")
+	}
+	messages := map[string]bool{}
+	for _, e := range err.(BuilderErrors) {
+		messages[e.Error()] = true
+	}
+	for _, msg := range []string{
+		`bet[0].stake[1].type: only one "cash" stake is allowed per bet`,
+		"bet[0].stake[1].currency: currency USD differs from EUR",
+		`bet[0].stake[1].mode: mode "unit" differs from "total"`,
+		"bet[1].stake: stake is required",
+	} {
+		if !messages[msg] {
+			t.Errorf("Expected error %q, got %v", msg, err)
+		}
+	}
+}
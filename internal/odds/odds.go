@@ -0,0 +1,309 @@
+// Package odds parses and formats prices in decimal, fractional, American,
+// Hong Kong, Malay and Indonesian notation. Prices are held as exact
+// rationals, so converting 10/11 to decimal and back yields 10/11 again.
+package odds
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/gdsZyy/mts-service/internal/decimal"
+)
+
+// Format identifies an odds notation
+type Format string
+
+const (
+	Decimal    Format = "decimal"    // 3.50
+	Fractional Format = "fractional" // 5/2
+	American   Format = "american"   // +250, -110
+	HongKong   Format = "hongkong"   // 2.50 (decimal - 1)
+	Malay      Format = "malay"      // 0.50, -0.40
+	Indonesian Format = "indonesian" // 2.50, -2.50
+)
+
+// SubmitScale is the number of decimals converted odds are sent to MTS with
+const SubmitScale = 4
+
+// DisplayScale is the number of decimals used when rendering decimal-style formats
+const DisplayScale = 2
+
+var formatAliases = map[string]Format{
+	"":           Decimal,
+	"decimal":    Decimal,
+	"eu":         Decimal,
+	"fractional": Fractional,
+	"uk":         Fractional,
+	"american":   American,
+	"us":         American,
+	"moneyline":  American,
+	"hongkong":   HongKong,
+	"hk":         HongKong,
+	"malay":      Malay,
+	"my":         Malay,
+	"indonesian": Indonesian,
+	"indo":       Indonesian,
+	"id":         Indonesian,
+}
+
+// ParseFormat resolves a format name or alias; an empty name means decimal
+func ParseFormat(name string) (Format, error) {
+	if f, ok := formatAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown odds format %q", name)
+}
+
+// Price is a decimal price (stake-inclusive payout per unit) held exactly
+type Price struct {
+	rat *big.Rat
+}
+
+var (
+	ratOne     = big.NewRat(1, 1)
+	ratHundred = big.NewRat(100, 1)
+)
+
+// FromDecimal returns the price of decimal odds d
+func FromDecimal(d decimal.Decimal) (Price, error) {
+	r, ok := new(big.Rat).SetString(d.String())
+	if !ok {
+		return Price{}, fmt.Errorf("invalid decimal odds %s", d)
+	}
+	return newPrice(r)
+}
+
+func newPrice(r *big.Rat) (Price, error) {
+	if r.Cmp(ratOne) <= 0 {
+		return Price{}, fmt.Errorf("odds must be greater than 1 in decimal terms, got %s", r.FloatString(4))
+	}
+	return Price{rat: r}, nil
+}
+
+// parseRat reads a plain decimal string exactly
+func parseRat(value string) (*big.Rat, error) {
+	d, err := decimal.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	r, _ := new(big.Rat).SetString(d.String())
+	return r, nil
+}
+
+// Parse reads odds written in the given format
+func Parse(value string, format Format) (Price, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Price{}, fmt.Errorf("odds value is empty")
+	}
+
+	switch format {
+	case Decimal, "":
+		r, err := parseRat(value)
+		if err != nil {
+			return Price{}, err
+		}
+		return newPrice(r)
+
+	case Fractional:
+		if lower := strings.ToLower(value); lower == "evens" || lower == "evs" {
+			return newPrice(big.NewRat(2, 1))
+		}
+		parts := strings.SplitN(value, "/", 2)
+		if len(parts) != 2 {
+			return Price{}, fmt.Errorf("fractional odds %q must look like 5/2", value)
+		}
+		num, err := parseRat(parts[0])
+		if err != nil {
+			return Price{}, err
+		}
+		den, err := parseRat(parts[1])
+		if err != nil {
+			return Price{}, err
+		}
+		if num.Sign() <= 0 || den.Sign() <= 0 {
+			return Price{}, fmt.Errorf("fractional odds %q must be positive", value)
+		}
+		return newPrice(new(big.Rat).Add(ratOne, new(big.Rat).Quo(num, den)))
+
+	case American:
+		r, err := parseRat(value)
+		if err != nil {
+			return Price{}, err
+		}
+		if new(big.Rat).Abs(r).Cmp(ratHundred) < 0 {
+			return Price{}, fmt.Errorf("american odds %q must be at least +100 or at most -100", value)
+		}
+		if r.Sign() > 0 {
+			// +250 pays 250 per 100 staked
+			return newPrice(new(big.Rat).Add(ratOne, new(big.Rat).Quo(r, ratHundred)))
+		}
+		// -110 needs 110 staked to win 100
+		return newPrice(new(big.Rat).Add(ratOne, new(big.Rat).Quo(ratHundred, new(big.Rat).Neg(r))))
+
+	case HongKong:
+		r, err := parseRat(value)
+		if err != nil {
+			return Price{}, err
+		}
+		if r.Sign() <= 0 {
+			return Price{}, fmt.Errorf("hong kong odds %q must be positive", value)
+		}
+		return newPrice(new(big.Rat).Add(ratOne, r))
+
+	case Malay:
+		r, err := parseRat(value)
+		if err != nil {
+			return Price{}, err
+		}
+		if r.Sign() == 0 || new(big.Rat).Abs(r).Cmp(ratOne) > 0 {
+			return Price{}, fmt.Errorf("malay odds %q must be between -1 and 1 and not 0", value)
+		}
+		return newPrice(asianToDecimal(r))
+
+	case Indonesian:
+		r, err := parseRat(value)
+		if err != nil {
+			return Price{}, err
+		}
+		if new(big.Rat).Abs(r).Cmp(ratOne) < 0 {
+			return Price{}, fmt.Errorf("indonesian odds %q must be at least 1 or at most -1", value)
+		}
+		return newPrice(asianToDecimal(r))
+	}
+	return Price{}, fmt.Errorf("unknown odds format %q", format)
+}
+
+// asianToDecimal converts Malay/Indonesian odds: positive values are the
+// profit per unit, negative values the stake needed to win one unit
+func asianToDecimal(r *big.Rat) *big.Rat {
+	if r.Sign() > 0 {
+		return new(big.Rat).Add(ratOne, r)
+	}
+	return new(big.Rat).Add(ratOne, new(big.Rat).Quo(ratOne, new(big.Rat).Neg(r)))
+}
+
+// Decimal returns the price as decimal odds rounded down to scale places,
+// so a converted price never pays more than the customer was shown
+func (p Price) Decimal(scale int) decimal.Decimal {
+	num, _ := decimal.Parse(p.rat.Num().String())
+	den, _ := decimal.Parse(p.rat.Denom().String())
+	return num.Div(den, scale, decimal.RoundDown)
+}
+
+// Submit returns the decimal odds string sent to MTS
+func (p Price) Submit() string {
+	return p.Decimal(SubmitScale).Normalize().String()
+}
+
+// Format renders the price in the given notation
+func (p Price) Format(format Format) string {
+	profit := new(big.Rat).Sub(p.rat, ratOne)
+
+	switch format {
+	case Fractional:
+		return formatFraction(profit)
+	case American:
+		if p.rat.Cmp(big.NewRat(2, 1)) >= 0 {
+			return "+" + formatRat(new(big.Rat).Mul(profit, ratHundred), DisplayScale, true)
+		}
+		return "-" + formatRat(new(big.Rat).Quo(ratHundred, profit), DisplayScale, true)
+	case HongKong:
+		return formatRat(profit, DisplayScale, false)
+	case Malay:
+		if profit.Cmp(ratOne) <= 0 {
+			return formatRat(profit, DisplayScale, false)
+		}
+		return "-" + formatRat(new(big.Rat).Inv(profit), DisplayScale, false)
+	case Indonesian:
+		if profit.Cmp(ratOne) >= 0 {
+			return formatRat(profit, DisplayScale, false)
+		}
+		return "-" + formatRat(new(big.Rat).Inv(profit), DisplayScale, false)
+	}
+	return formatRat(p.rat, DisplayScale, false)
+}
+
+// maxExactDenominator bounds the fractions rendered as they are. Decimal
+// odds from MTS carry up to 4 places, so 10/11 comes back as 1.9090, which is
+// 909/1000 exactly; such prices are shown as the nearest ladder fraction.
+const maxExactDenominator = 20
+
+// ladder lists the conventional fractional prices by increasing profit,
+// written the way bookmakers quote them (4/6 rather than 2/3)
+var ladder = []struct{ num, den int64 }{
+	{1, 100}, {1, 50}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14}, {1, 12},
+	{1, 10}, {1, 9}, {1, 8}, {2, 15}, {1, 7}, {1, 6}, {2, 11}, {1, 5}, {2, 9},
+	{1, 4}, {2, 7}, {3, 10}, {1, 3}, {4, 11}, {2, 5}, {4, 9}, {1, 2}, {8, 15},
+	{4, 7}, {8, 13}, {4, 6}, {8, 11}, {4, 5}, {5, 6}, {10, 11}, {1, 1},
+	{11, 10}, {6, 5}, {5, 4}, {11, 8}, {6, 4}, {8, 5}, {13, 8}, {7, 4},
+	{15, 8}, {2, 1}, {85, 40}, {9, 4}, {5, 2}, {11, 4}, {3, 1}, {10, 3},
+	{7, 2}, {4, 1}, {9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2}, {7, 1}, {15, 2},
+	{8, 1}, {17, 2}, {9, 1}, {10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1},
+	{18, 1}, {20, 1}, {22, 1}, {25, 1}, {28, 1}, {33, 1}, {40, 1}, {50, 1},
+	{66, 1}, {80, 1}, {100, 1}, {125, 1}, {150, 1}, {200, 1}, {250, 1},
+	{300, 1}, {400, 1}, {500, 1}, {750, 1}, {1000, 1},
+}
+
+// formatFraction renders a profit as a fraction: a ladder price if it is
+// one, itself if its denominator is small, otherwise the nearest ladder
+// price, or the nearest whole number beyond the top of the ladder
+func formatFraction(profit *big.Rat) string {
+	nearest, nearestDiff := -1, new(big.Rat)
+	for i, step := range ladder {
+		diff := new(big.Rat).Sub(big.NewRat(step.num, step.den), profit)
+		if diff.Sign() == 0 {
+			return fmt.Sprintf("%d/%d", step.num, step.den)
+		}
+		diff.Abs(diff)
+		if nearest < 0 || diff.Cmp(nearestDiff) < 0 {
+			nearest, nearestDiff = i, diff
+		}
+	}
+	if profit.Denom().Cmp(big.NewInt(maxExactDenominator)) <= 0 {
+		return profit.Num().String() + "/" + profit.Denom().String()
+	}
+	top := ladder[len(ladder)-1]
+	if profit.Cmp(big.NewRat(top.num, top.den)) > 0 {
+		return formatRat(profit, 0, true) + "/1"
+	}
+	step := ladder[nearest]
+	return fmt.Sprintf("%d/%d", step.num, step.den)
+}
+
+// formatRat rounds half-up to scale places, optionally trimming trailing zeros
+func formatRat(r *big.Rat, scale int, trim bool) string {
+	num, _ := decimal.Parse(r.Num().String())
+	den, _ := decimal.Parse(r.Denom().String())
+	d := num.Div(den, scale, decimal.RoundHalfUp)
+	if trim {
+		d = d.Normalize()
+	}
+	return d.String()
+}
+
+// ToDecimal converts odds in any format to the decimal string sent to MTS.
+// Decimal input is passed through unchanged so existing prices keep their digits.
+func ToDecimal(value string, format Format) (string, error) {
+	price, err := Parse(value, format)
+	if err != nil {
+		return "", err
+	}
+	if format == Decimal || format == "" {
+		return strings.TrimSpace(value), nil
+	}
+	return price.Submit(), nil
+}
+
+// Render converts decimal odds to the given format for display
+func Render(decimalOdds string, format Format) (string, error) {
+	if format == Decimal || format == "" {
+		return decimalOdds, nil
+	}
+	price, err := Parse(decimalOdds, Decimal)
+	if err != nil {
+		return "", err
+	}
+	return price.Format(format), nil
+}
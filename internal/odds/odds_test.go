@@ -0,0 +1,128 @@
+package odds
+
+import "testing"
+
+func TestParseToDecimal(t *testing.T) {
+	tests := []struct {
+		value    string
+		format   Format
+		expected string
+	}{
+		{"2.50", Decimal, "2.50"},
+		{"5/2", Fractional, "3.5"},
+		{"evens", Fractional, "2"},
+		{"10/11", Fractional, "1.909"},
+		{"+250", American, "3.5"},
+		{"-110", American, "1.909"},
+		{"100", American, "2"},
+		{"0.91", HongKong, "1.91"},
+		{"0.50", Malay, "1.5"},
+		{"-0.40", Malay, "3.5"},
+		{"2.50", Indonesian, "3.5"},
+		{"-2.50", Indonesian, "1.4"},
+	}
+
+	for _, tt := range tests {
+		got, err := ToDecimal(tt.value, tt.format)
+		if err != nil {
+			t.Errorf("ToDecimal(%q, %s) failed: %v", tt.value, tt.format, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ToDecimal(%q, %s) = %s, expected %s", tt.value, tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		value  string
+		format Format
+	}{
+		{"1.00", Decimal},
+		{"abc", Decimal},
+		{"5-2", Fractional},
+		{"0/1", Fractional},
+		{"+50", American},
+		{"0", HongKong},
+		{"1.5", Malay},
+		{"0", Malay},
+		{"0.5", Indonesian},
+		{"", Decimal},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.value, tt.format); err == nil {
+			t.Errorf("Parse(%q, %s) expected error", tt.value, tt.format)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	price, err := Parse("10/11", Fractional)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := map[Format]string{
+		Decimal:    "1.91",
+		Fractional: "10/11",
+		American:   "-110",
+		HongKong:   "0.91",
+		Malay:      "0.91",
+		Indonesian: "-1.10",
+	}
+	for format, want := range expected {
+		if got := price.Format(format); got != want {
+			t.Errorf("Format(%s) = %s, expected %s", format, got, want)
+		}
+	}
+
+	long, _ := Parse("3.5", Decimal)
+	if got := long.Format(American); got != "+250" {
+		t.Errorf("Format(american) = %s, expected +250", got)
+	}
+	if got := long.Format(Malay); got != "-0.40" {
+		t.Errorf("Format(malay) = %s, expected -0.40", got)
+	}
+	if got := long.Format(Fractional); got != "5/2" {
+		t.Errorf("Format(fractional) = %s, expected 5/2", got)
+	}
+}
+
+func TestRenderFractionalSnapsToLadder(t *testing.T) {
+	expected := map[string]string{
+		"1.9090":  "10/11", // 10/11 truncated to 4 places by MTS
+		"1.91":    "10/11",
+		"1.6667":  "4/6",
+		"1.6":     "3/5", // Small denominator, kept exactly
+		"2.05":    "21/20",
+		"4.33":    "10/3",
+		"1.44":    "4/9",
+		"2.5":     "6/4",
+		"3.5":     "5/2",
+		"1201.37": "1200/1", // Beyond the ladder
+	}
+	for value, want := range expected {
+		got, err := Render(value, Fractional)
+		if err != nil {
+			t.Errorf("Render(%s) failed: %v", value, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Render(%s, fractional) = %s, expected %s", value, got, want)
+		}
+	}
+}
+
+func TestParseFormatAliases(t *testing.T) {
+	for name, want := range map[string]Format{"": Decimal, "UK": Fractional, "us": American, "HK": HongKong, "indo": Indonesian} {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %s, %v; expected %s", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("roman"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,340 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// compiledSchema interprets the subset of JSON Schema draft-07 used by the
+// embedded MTS schemas: type, enum, const, required, properties,
+// additionalProperties, items, min/maxItems, uniqueItems, min/maxLength,
+// pattern, minimum, maximum, allOf, anyOf, oneOf, if/then/else and local $ref.
+type compiledSchema struct {
+	root     map[string]interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+func newCompiledSchema(root map[string]interface{}) *compiledSchema {
+	s := &compiledSchema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	s.compilePatterns(root)
+	return s
+}
+
+func (s *compiledSchema) validate(doc interface{}) []FieldError {
+	var errs []FieldError
+	s.check(s.root, doc, "", &errs)
+	return errs
+}
+
+// compilePatterns precompiles every "pattern" keyword so validation never
+// mutates the schema and is safe for concurrent use afterwards
+func (s *compiledSchema) compilePatterns(node interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if p, ok := value.(string); ok && key == "pattern" {
+				s.patterns[p] = regexp.MustCompile(p)
+				continue
+			}
+			s.compilePatterns(value)
+		}
+	case []interface{}:
+		for _, item := range n {
+			s.compilePatterns(item)
+		}
+	}
+}
+
+func (s *compiledSchema) resolve(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		panic(fmt.Sprintf("schema: unsupported $ref %q", ref))
+	}
+	var node interface{} = s.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			panic(fmt.Sprintf("schema: unresolvable $ref %q", ref))
+		}
+		node = m[part]
+	}
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		panic(fmt.Sprintf("schema: unresolvable $ref %q", ref))
+	}
+	return m
+}
+
+func (s *compiledSchema) matches(schema map[string]interface{}, value interface{}, path string) bool {
+	var errs []FieldError
+	s.check(schema, value, path, &errs)
+	return len(errs) == 0
+}
+
+func (s *compiledSchema) check(schema map[string]interface{}, value interface{}, path string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		s.check(s.resolve(ref), value, path, errs)
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		fail("must be of type %v, got %s", t, typeName(value))
+		return
+	}
+
+	if c, ok := schema["const"]; ok && !equalJSON(c, value) {
+		fail("must be %s", formatJSON(c))
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equalJSON(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s, got %s", formatJSON(enum), formatJSON(value))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.checkObject(schema, v, path, errs)
+	case []interface{}:
+		s.checkArray(schema, v, path, errs)
+	case string:
+		if min, ok := intKeyword(schema, "minLength"); ok && len(v) < min {
+			fail("must be at least %d characters", min)
+		}
+		if max, ok := intKeyword(schema, "maxLength"); ok && len(v) > max {
+			fail("must be at most %d characters", max)
+		}
+		if p, ok := schema["pattern"].(string); ok && !s.patterns[p].MatchString(v) {
+			fail("%q does not match pattern %s", v, p)
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if min, ok := schema["minimum"].(json.Number); ok {
+			if m, _ := min.Float64(); f < m {
+				fail("must be >= %s", min)
+			}
+		}
+		if max, ok := schema["maximum"].(json.Number); ok {
+			if m, _ := max.Float64(); f > m {
+				fail("must be <= %s", max)
+			}
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			s.check(sub.(map[string]interface{}), value, path, errs)
+		}
+	}
+
+	if alternatives, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range alternatives {
+			if s.matches(sub.(map[string]interface{}), value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one of the allowed shapes")
+		}
+	}
+
+	if one, ok := schema["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range one {
+			if s.matches(sub.(map[string]interface{}), value, path) {
+				count++
+			}
+		}
+		if count != 1 {
+			fail("must match exactly one of the allowed shapes, matched %d", count)
+		}
+	}
+
+	if cond, ok := schema["if"].(map[string]interface{}); ok {
+		if s.matches(cond, value, path) {
+			if then, ok := schema["then"].(map[string]interface{}); ok {
+				s.check(then, value, path, errs)
+			}
+		} else if els, ok := schema["else"].(map[string]interface{}); ok {
+			s.check(els, value, path, errs)
+		}
+	}
+}
+
+func (s *compiledSchema) checkObject(schema map[string]interface{}, obj map[string]interface{}, path string, errs *[]FieldError) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name := r.(string)
+			if _, present := obj[name]; !present {
+				*errs = append(*errs, FieldError{Path: joinPath(path, name), Message: "is required"})
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+
+	// Iterate in key order so error lists are deterministic
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := joinPath(path, key)
+		if propSchema, ok := props[key].(map[string]interface{}); ok {
+			s.check(propSchema, obj[key], childPath, errs)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				*errs = append(*errs, FieldError{Path: childPath, Message: "is not allowed"})
+			}
+		case map[string]interface{}:
+			s.check(extra, obj[key], childPath, errs)
+		}
+	}
+}
+
+func (s *compiledSchema) checkArray(schema map[string]interface{}, arr []interface{}, path string, errs *[]FieldError) {
+	if min, ok := intKeyword(schema, "minItems"); ok && len(arr) < min {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf("must contain at least %d item(s)", min)})
+	}
+	if max, ok := intKeyword(schema, "maxItems"); ok && len(arr) > max {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf("must contain at most %d item(s)", max)})
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if equalJSON(arr[i], arr[j]) {
+					*errs = append(*errs, FieldError{Path: fmt.Sprintf("%s[%d]", path, j), Message: fmt.Sprintf("duplicates item %d", i)})
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			s.check(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+func matchesType(t interface{}, value interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, value)
+	case []interface{}:
+		for _, candidate := range tt {
+			if name, ok := candidate.(string); ok && isType(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	}
+	return false
+}
+
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func intKeyword(schema map[string]interface{}, key string) (int, bool) {
+	n, ok := schema[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func equalJSON(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, _ := na.Float64()
+			fb, _ := nb.Float64()
+			return fa == fb
+		}
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// checkTicketRules applies the ticket-placement constraints that cannot be
+// expressed in JSON Schema: system sizes must not exceed the number of nested
+// selections, a bet may contain at most one top-level system selection (the
+// banker layout is one system plus standard banker selections), and bets with
+// a system selection must use a "unit" stake.
+func checkTicketRules(doc interface{}) []FieldError {
+	var errs []FieldError
+
+	root, _ := doc.(map[string]interface{})
+	content, _ := root["content"].(map[string]interface{})
+	bets, _ := content["bets"].([]interface{})
+
+	for i, b := range bets {
+		bet, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		betPath := fmt.Sprintf("content.bets[%d]", i)
+		selections, _ := bet["selections"].([]interface{})
+
+		systemCount := 0
+		for j, s := range selections {
+			sel, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			selPath := fmt.Sprintf("%s.selections[%d]", betPath, j)
+			if sel["type"] == "system" {
+				systemCount++
+				if systemCount > 1 {
+					errs = append(errs, FieldError{Path: selPath, Message: "a bet may contain at most one system selection"})
+				}
+			}
+			errs = append(errs, checkSystemSizes(sel, selPath)...)
+		}
+
+		if systemCount > 0 {
+			stakes, _ := bet["stake"].([]interface{})
+			for k, st := range stakes {
+				stake, ok := st.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if stake["mode"] != "unit" {
+					errs = append(errs, FieldError{
+						Path:    fmt.Sprintf("%s.stake[%d].mode", betPath, k),
+						Message: "system bets require stake mode \"unit\"",
+					})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkSystemSizes verifies every size of a (possibly nested) system selection
+// is not larger than its number of nested selections
+func checkSystemSizes(sel map[string]interface{}, path string) []FieldError {
+	if sel["type"] != "system" {
+		return nil
+	}
+
+	var errs []FieldError
+	nested, _ := sel["selections"].([]interface{})
+	sizes, _ := sel["size"].([]interface{})
+	for i, s := range sizes {
+		n, ok := s.(json.Number)
+		if !ok {
+			continue
+		}
+		if size, err := n.Int64(); err == nil && int(size) > len(nested) {
+			errs = append(errs, FieldError{
+				Path:    fmt.Sprintf("%s.size[%d]", path, i),
+				Message: fmt.Sprintf("size %d exceeds %d nested selections", size, len(nested)),
+			})
+		}
+	}
+
+	for i, n := range nested {
+		if child, ok := n.(map[string]interface{}); ok {
+			errs = append(errs, checkSystemSizes(child, fmt.Sprintf("%s.selections[%d]", path, i))...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,160 @@
+// Package schema validates outgoing MTS Transaction 3.0 messages against the
+// embedded JSON schemas before they are written to the MTS WebSocket.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Mode controls what happens when an outgoing message violates the schema
+type Mode string
+
+const (
+	ModeOff     Mode = "off"     // Validation is skipped entirely
+	ModeLog     Mode = "log"     // Violations are logged, the message is still sent
+	ModeEnforce Mode = "enforce" // Violations are returned as errors and the message is not sent
+)
+
+// ParseMode converts a configuration string to a Mode. An empty string is
+// ModeLog; anything other than "off", "log" or "enforce" is an error.
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ModeLog, nil
+	case ModeOff, ModeLog, ModeEnforce:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown schema validation mode %q, expected off, log or enforce", value)
+	}
+}
+
+// FieldError describes a single violation at a JSON path (e.g. "content.bets[0].stake[0].amount")
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is returned when a message does not conform to its schema
+type ValidationError struct {
+	Operation string       `json:"operation"`
+	Errors    []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.String()
+	}
+	return fmt.Sprintf("%s message failed schema validation: %s", e.Operation, strings.Join(parts, "; "))
+}
+
+// schemas maps an MTS operation to its compiled schema
+var schemas = map[string]*compiledSchema{
+	"ticket-placement":  mustLoad("schemas/ticket-placement.json"),
+	"cashout-inform":    mustLoad("schemas/cashout-inform.json"),
+	"cashout-build":     mustLoad("schemas/cashout-build.json"),
+	"cashout-placement": mustLoad("schemas/cashout-placement.json"),
+}
+
+// rules holds semantic checks that JSON Schema cannot express, keyed by operation
+var rules = map[string]func(doc interface{}) []FieldError{
+	"ticket-placement": checkTicketRules,
+}
+
+// Validator checks outgoing messages against the MTS schemas
+type Validator struct {
+	mode Mode
+}
+
+// NewValidator creates a validator running in the given mode
+func NewValidator(mode Mode) *Validator {
+	return &Validator{mode: mode}
+}
+
+// Mode returns the validator's mode
+func (v *Validator) Mode() Mode {
+	if v == nil {
+		return ModeOff
+	}
+	return v.mode
+}
+
+// Validate checks a marshalled message and returns every violation found.
+// Messages whose operation has no schema (e.g. acknowledgements) are accepted.
+func (v *Validator) Validate(data []byte) *ValidationError {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return &ValidationError{Errors: []FieldError{{Message: fmt.Sprintf("invalid JSON: %v", err)}}}
+	}
+
+	obj, _ := doc.(map[string]interface{})
+	operation, _ := obj["operation"].(string)
+
+	s, ok := schemas[operation]
+	if !ok {
+		return nil
+	}
+
+	errs := s.validate(doc)
+	if rule, ok := rules[operation]; ok {
+		errs = append(errs, rule(doc)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Operation: operation, Errors: errs}
+}
+
+// Check validates a marshalled message according to the validator's mode.
+// It only returns an error in enforce mode; in log mode violations are logged.
+func (v *Validator) Check(data []byte) error {
+	if v.Mode() == ModeOff {
+		return nil
+	}
+
+	verr := v.Validate(data)
+	if verr == nil {
+		return nil
+	}
+
+	if v.mode == ModeEnforce {
+		return verr
+	}
+
+	log.Printf("Warning: %v", verr)
+	return nil
+}
+
+func mustLoad(name string) *compiledSchema {
+	data, err := schemaFiles.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("schema: failed to read %s: %v", name, err))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var root map[string]interface{}
+	if err := dec.Decode(&root); err != nil {
+		panic(fmt.Sprintf("schema: failed to parse %s: %v", name, err))
+	}
+	return newCompiledSchema(root)
+}
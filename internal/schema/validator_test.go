@@ -0,0 +1,252 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+func validTicket() *models.TicketRequest {
+	return &models.TicketRequest{
+		OperatorID:    45426,
+		CorrelationID: "corr-001",
+		TimestampUTC:  1764059527727,
+		Operation:     "ticket-placement",
+		Version:       "3.0",
+		Content: models.TicketContent{
+			Type:     "ticket",
+			TicketID: "ticket-001",
+			Bets: []models.Bet{
+				{
+					Selections: []models.Selection{
+						{
+							Type: "system",
+							Size: []int{2},
+							Selections: []models.Selection{
+								models.NewSelection("3", "sr:match:1", "1", "1", "2.50"),
+								models.NewSelection("3", "sr:match:2", "1", "2", "1.80"),
+								models.NewSelection("3", "sr:match:3", "1", "1", "3.00"),
+							},
+						},
+						models.NewSelection("3", "sr:match:4", "1", "1", "1.50"),
+					},
+					Stake: []models.Stake{models.NewStake("cash", "EUR", "1.00000000", "unit")},
+				},
+			},
+			Context: &models.Context{
+				Channel: &models.Channel{Type: "internet", Lang: "EN"},
+				LimitID: 4268,
+			},
+		},
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	return data
+}
+
+func hasPath(verr *ValidationError, path string) bool {
+	for _, fe := range verr.Errors {
+		if fe.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidTicketPasses(t *testing.T) {
+	v := NewValidator(ModeEnforce)
+	if verr := v.Validate(mustMarshal(t, validTicket())); verr != nil {
+		t.Fatalf("Expected valid ticket, got: %v", verr)
+	}
+}
+
+func TestTicketViolationsReportFieldPaths(t *testing.T) {
+	ticket := validTicket()
+	ticket.Content.Bets[0].Stake[0].Amount = "1.123456789"  // 9 decimals
+	ticket.Content.Bets[0].Stake[0].Mode = "total"          // system bets need unit
+	ticket.Content.Bets[0].Selections[0].Size = []int{2, 4} // 4 > 3 nested selections
+	ticket.Content.Bets[0].Selections[1].Odds = nil
+
+	verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, ticket))
+	if verr == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	expected := []string{
+		"content.bets[0].stake[0].amount",
+		"content.bets[0].stake[0].mode",
+		"content.bets[0].selections[0].size[1]",
+		"content.bets[0].selections[1].odds",
+	}
+	for _, path := range expected {
+		if !hasPath(verr, path) {
+			t.Errorf("Expected error at %s, got: %v", path, verr)
+		}
+	}
+}
+
+func TestMultipleSystemSelectionsRejected(t *testing.T) {
+	ticket := validTicket()
+	bet := &ticket.Content.Bets[0]
+	bet.Selections = append(bet.Selections, bet.Selections[0])
+
+	verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, ticket))
+	if verr == nil || !hasPath(verr, "content.bets[0].selections[2]") {
+		t.Fatalf("Expected second system selection to be rejected, got: %v", verr)
+	}
+}
+
+func TestExternalAndCustomBetSelections(t *testing.T) {
+	ticket := validTicket()
+	bet := &ticket.Content.Bets[0]
+	bet.Selections = []models.Selection{
+		models.NewExternalSelection("op:event:77", "winner", "home", "1.90"),
+		models.NewCustomBetSelection("3", "sr:match:5", "4.20",
+			models.NewCustomBetLeg("1", "1"),
+			models.NewCustomBetLeg("18", "12", "total=2.5"),
+		),
+	}
+	bet.Stake[0].Mode = "total"
+
+	v := NewValidator(ModeEnforce)
+	if verr := v.Validate(mustMarshal(t, ticket)); verr != nil {
+		t.Fatalf("Expected valid ticket, got: %v", verr)
+	}
+
+	bet.Selections[1].Selections = bet.Selections[1].Selections[:1]
+	verr := v.Validate(mustMarshal(t, ticket))
+	if verr == nil || !hasPath(verr, "content.bets[0].selections[1].selections") {
+		t.Fatalf("Expected single-leg custom bet to be rejected, got: %v", verr)
+	}
+}
+
+func TestCashoutPartialRequiresPercentage(t *testing.T) {
+	cashout := &models.CashoutRequest{
+		OperatorID:    45426,
+		CorrelationID: "cashout-corr-1",
+		TimestampUTC:  1764059527727,
+		Operation:     "cashout-inform",
+		Version:       "3.0",
+		Content: models.CashoutContent{
+			Type: "cashout-inform",
+			Cashout: models.CashoutInfo{
+				Type:      "cashout",
+				CashoutID: "cashout-1",
+				Details: models.CashoutDetail{
+					Type:            "ticket-partial",
+					TicketID:        "ticket-001",
+					TicketSignature: "sig",
+					Code:            100,
+					Payout:          []models.CashoutPayout{{Type: "cash", Currency: "EUR", Amount: "5.00"}},
+				},
+			},
+			Validation: &models.CashoutValidation{Code: 1100, Message: "Cashout accepted"},
+		},
+	}
+
+	verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, cashout))
+	if verr == nil || !hasPath(verr, "content.cashout.details.percentage") {
+		t.Fatalf("Expected missing percentage error, got: %v", verr)
+	}
+
+	cashout.Content.Cashout.Details.Percentage = "0.5"
+	if verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, cashout)); verr != nil {
+		t.Fatalf("Expected valid cashout, got: %v", verr)
+	}
+}
+
+func TestCheckModes(t *testing.T) {
+	ticket := validTicket()
+	ticket.Content.Bets[0].Stake[0].Amount = "-1"
+	data := mustMarshal(t, ticket)
+
+	if err := NewValidator(ModeLog).Check(data); err != nil {
+		t.Errorf("Log mode should not return an error, got: %v", err)
+	}
+	if err := NewValidator(ModeOff).Check(data); err != nil {
+		t.Errorf("Off mode should not return an error, got: %v", err)
+	}
+	err := NewValidator(ModeEnforce).Check(data)
+	if err == nil || !strings.Contains(err.Error(), "content.bets[0].stake[0].amount") {
+		t.Errorf("Enforce mode should return a field-path error, got: %v", err)
+	}
+}
+
+func TestUnknownOperationIsAccepted(t *testing.T) {
+	ack := models.TicketAck{Operation: "ticket-placement-ack"}
+	if verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, ack)); verr != nil {
+		t.Errorf("Expected acknowledgements to be skipped, got: %v", verr)
+	}
+}
+
+func TestStakeModeRequired(t *testing.T) {
+	ticket := validTicket()
+	ticket.Content.Bets[0].Stake[0].Mode = ""
+
+	verr := NewValidator(ModeEnforce).Validate(mustMarshal(t, ticket))
+	if verr == nil || !hasPath(verr, "content.bets[0].stake[0].mode") {
+		t.Fatalf("Expected missing mode error, got: %v", verr)
+	}
+}
+
+func TestCashoutBuildAndPlacement(t *testing.T) {
+	cashout := &models.CashoutRequest{
+		OperatorID:    45426,
+		CorrelationID: "cashout-corr-2",
+		TimestampUTC:  1764059527727,
+		Content: models.CashoutContent{
+			Cashout: models.CashoutInfo{
+				Type:      "cashout",
+				CashoutID: "cashout-2",
+				Details: models.CashoutDetail{
+					Type:            "ticket",
+					TicketID:        "ticket-001",
+					TicketSignature: "sig",
+					Code:            100,
+					Payout:          []models.CashoutPayout{{Type: "cash", Currency: "EUR", Amount: "5.00"}},
+				},
+			},
+		},
+	}
+
+	v := NewValidator(ModeEnforce)
+	for _, operation := range []string{"cashout-build", "cashout-placement"} {
+		cashout.Operation, cashout.Version, cashout.Content.Type = operation, "3.0", operation
+		cashout.Content.Validation = nil
+		if verr := v.Validate(mustMarshal(t, cashout)); verr != nil {
+			t.Errorf("%s: expected valid request, got: %v", operation, verr)
+		}
+
+		cashout.Content.Validation = &models.CashoutValidation{Code: 1100, Message: "Cashout accepted"}
+		if verr := v.Validate(mustMarshal(t, cashout)); verr == nil || !hasPath(verr, "content.validation") {
+			t.Errorf("%s: expected validation to be rejected, got: %v", operation, verr)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		value string
+		mode  Mode
+		err   bool
+	}{
+		{"", ModeLog, false},
+		{"off", ModeOff, false},
+		{" Log ", ModeLog, false},
+		{"ENFORCE", ModeEnforce, false},
+		{"enforec", "", true},
+	}
+	for _, tt := range tests {
+		mode, err := ParseMode(tt.value)
+		if mode != tt.mode || (err != nil) != tt.err {
+			t.Errorf("ParseMode(%q) = %q, %v", tt.value, mode, err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/gdsZyy/mts-service/internal/config"
 	"github.com/gdsZyy/mts-service/internal/models"
+	"github.com/gdsZyy/mts-service/internal/schema"
 	"github.com/gorilla/websocket"
 )
 
@@ -70,6 +71,20 @@ type MTSService struct {
 	sentMessages map[string]*models.TicketResponse // Key: JSON hash of the message
 	sentMsgMu    sync.RWMutex
 	
+	// Alternative stake offers awaiting acceptance, by rejected ticket ID
+	reoffers     map[string]*models.Reoffer
+	reofferMu    sync.Mutex
+	
+	// Pre-send validation of outgoing messages against the MTS schemas
+	validator    *schema.Validator
+	
+	// Offline queue for requests submitted while disconnected (nil when disabled)
+	queue        *outboundQueue
+	queueTTL     time.Duration
+	flushMu      sync.Mutex
+	clock        clock
+	send         func(msg interface{}) error // Writes to the active connection; sendMessage outside tests
+	
 	ctx          context.Context
 	cancel       context.CancelFunc
 	connected    int32 // atomic flag for connection status
@@ -87,17 +102,36 @@ func NewMTSService(cfg *config.Config) *MTSService {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &MTSService{
+	// Load rejects an invalid mode; a Config built elsewhere falls back to logging
+	schemaMode, err := cfg.SchemaMode()
+	if err != nil {
+		log.Printf("Warning: %v; logging schema violations", err)
+		schemaMode = schema.ModeLog
+	}
+
+	var queue *outboundQueue
+	if cfg.OfflineQueueSize > 0 {
+		queue = newOutboundQueue(cfg.OfflineQueueSize)
+	}
+
+	s := &MTSService{
 		cfg:          cfg,
 		wsURL:        wsURL,
 		wsAudience:   wsAudience,
 			responses:        make(map[string]chan *models.TicketResponse),
 			cashoutResponses: make(map[string]chan *models.CashoutResponse),
 			sentMessages:     make(map[string]*models.TicketResponse),
+			reoffers:         make(map[string]*models.Reoffer),
+		validator:    schema.NewValidator(schemaMode),
+		queue:        queue,
+		queueTTL:     cfg.OfflineQueueTTL,
+		clock:        systemClock{},
 		ctx:          ctx,
 		cancel:       cancel,
 		httpClient:   &http.Client{Timeout: 30 * time.Second},
 	}
+	s.send = s.sendMessage
+	return s
 }
 
 func (s *MTSService) Start() error {
@@ -247,6 +281,9 @@ func (s *MTSService) connect() error {
 
 	log.Printf("Connected to MTS WebSocket (ID: %s)", connID)
 
+	// Send anything submitted while we were disconnected
+	go s.flushQueue()
+
 	// NOTE: Initialization message sending is disabled to prevent rate limiting
 	// The service will only send messages when explicitly requested via API
 	// if err := s.sendInitializationMessage(); err != nil {
@@ -655,17 +692,13 @@ func (s *MTSService) sendAcknowledgement(response *models.TicketResponse) error
 }
 
 func (s *MTSService) SendTicket(ticket *models.TicketRequest) (*models.TicketResponse, error) {
-	s.connMu.RLock()
-	activeConn := s.activeConn
-	s.connMu.RUnlock()
-
-	if activeConn == nil || atomic.LoadInt32(&s.connected) != 1 {
-		return nil, fmt.Errorf("not connected to MTS")
-	}
-
-	// Increment pending responses counter
-	atomic.AddInt32(&activeConn.pendingResponses, 1)
+	return s.SendTicketWithTTL(ticket, s.queueTTL)
+}
 
+// SendTicketWithTTL sends a ticket and waits for the response. If MTS is
+// disconnected and the offline queue is enabled, the ticket is held for up to
+// queueTTL and sent after reconnect; ErrQueueTTLExpired is returned otherwise.
+func (s *MTSService) SendTicketWithTTL(ticket *models.TicketRequest, queueTTL time.Duration) (*models.TicketResponse, error) {
 	responseCh := make(chan *models.TicketResponse, 1)
 	s.responseMu.Lock()
 	s.responses[ticket.CorrelationID] = responseCh
@@ -678,8 +711,11 @@ func (s *MTSService) SendTicket(ticket *models.TicketRequest) (*models.TicketRes
 		close(responseCh)
 	}()
 
-	if err := s.sendMessage(ticket); err != nil {
-		atomic.AddInt32(&activeConn.pendingResponses, -1)
+	activeConn, err := s.dispatch(ticket, "ticket "+ticket.Content.TicketID, queueTTL)
+	if err != nil {
+		if err == ErrNotConnected || err == ErrQueueFull || err == ErrQueueTTLExpired {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to send ticket: %w", err)
 	}
 
@@ -700,17 +736,11 @@ func (s *MTSService) SendTicket(ticket *models.TicketRequest) (*models.TicketRes
 
 // SendCashout sends a cashout request to MTS and waits for the response
 func (s *MTSService) SendCashout(cashout *models.CashoutRequest) (*models.CashoutResponse, error) {
-	s.connMu.RLock()
-	activeConn := s.activeConn
-	s.connMu.RUnlock()
-
-	if activeConn == nil || atomic.LoadInt32(&s.connected) != 1 {
-		return nil, fmt.Errorf("not connected to MTS")
-	}
-
-	// Increment pending responses counter
-	atomic.AddInt32(&activeConn.pendingResponses, 1)
+	return s.SendCashoutWithTTL(cashout, s.queueTTL)
+}
 
+// SendCashoutWithTTL sends a cashout request, queueing it for up to queueTTL while disconnected
+func (s *MTSService) SendCashoutWithTTL(cashout *models.CashoutRequest, queueTTL time.Duration) (*models.CashoutResponse, error) {
 	responseCh := make(chan *models.CashoutResponse, 1)
 	s.responseMu.Lock()
 	s.cashoutResponses[cashout.CorrelationID] = responseCh
@@ -723,8 +753,11 @@ func (s *MTSService) SendCashout(cashout *models.CashoutRequest) (*models.Cashou
 		close(responseCh)
 	}()
 
-	if err := s.sendMessage(cashout); err != nil {
-		atomic.AddInt32(&activeConn.pendingResponses, -1)
+	activeConn, err := s.dispatch(cashout, "cashout "+cashout.Content.Cashout.CashoutID, queueTTL)
+	if err != nil {
+		if err == ErrNotConnected || err == ErrQueueFull || err == ErrQueueTTLExpired {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to send cashout: %w", err)
 	}
 
@@ -827,6 +860,11 @@ func (s *MTSService) sendMessage(msg interface{}) error {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
+	// Validate against the MTS schema before it reaches the wire
+	if err := s.validator.Check(data); err != nil {
+		return err
+	}
+
 	// Log the message content
 	logMessage := string(data)
 	logMessage = strings.ReplaceAll(logMessage, "\n", "\t")
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotConnected is returned when MTS is unreachable and the request cannot be queued
+	ErrNotConnected = errors.New("not connected to MTS")
+	// ErrQueueFull is returned when the offline queue has reached its capacity
+	ErrQueueFull = errors.New("not connected to MTS and offline queue is full")
+	// ErrQueueTTLExpired is returned when a queued request was not sent before its TTL elapsed
+	ErrQueueTTLExpired = errors.New("not connected to MTS: request expired in offline queue")
+)
+
+// queuedMessage is a placement or cashout request held while MTS is disconnected
+type queuedMessage struct {
+	msg       interface{}
+	label     string // For logging, e.g. "ticket abc"
+	expiresAt time.Time
+	done      chan sendResult // Receives exactly one result when flushed or expired
+	withdrawn bool            // Set under the queue lock once the TTL elapsed during a flush
+}
+
+// clock is the time source of the offline queue, replaced in tests
+type clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// sendResult reports which connection a message was written to
+type sendResult struct {
+	conn *ConnectionState
+	err  error
+}
+
+// outboundQueue is a bounded FIFO of requests submitted during short outages
+type outboundQueue struct {
+	mu       sync.Mutex
+	items    []*queuedMessage
+	capacity int
+}
+
+func newOutboundQueue(capacity int) *outboundQueue {
+	return &outboundQueue{capacity: capacity}
+}
+
+func (q *outboundQueue) push(m *queuedMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) >= q.capacity {
+		return ErrQueueFull
+	}
+	q.items = append(q.items, m)
+	return nil
+}
+
+// remove takes a message out of the queue, reporting whether it was still queued
+func (q *outboundQueue) remove(m *queuedMessage) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.removeLocked(m)
+}
+
+// withdraw is remove for a message whose TTL elapsed. If a flush holds the
+// message instead, it is marked so the flush answers it rather than
+// re-queueing it.
+func (q *outboundQueue) withdraw(m *queuedMessage) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.removeLocked(m) {
+		return true
+	}
+	m.withdrawn = true
+	return false
+}
+
+func (q *outboundQueue) removeLocked(m *queuedMessage) bool {
+	for i, item := range q.items {
+		if item == m {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// drain pops every queued message in submission order
+func (q *outboundQueue) drain() []*queuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// requeue puts unsent messages back at the front, ahead of anything queued
+// meanwhile, and returns those withdrawn during the flush instead
+func (q *outboundQueue) requeue(items []*queuedMessage) (withdrawn []*queuedMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var kept []*queuedMessage
+	for _, item := range items {
+		if item.withdrawn {
+			withdrawn = append(withdrawn, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	q.items = append(kept, q.items...)
+	return withdrawn
+}
+
+func (q *outboundQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// QueueLength returns the number of requests waiting in the offline queue
+func (s *MTSService) QueueLength() int {
+	if s.queue == nil {
+		return 0
+	}
+	return s.queue.len()
+}
+
+// deliver writes a message on the active connection and counts it as pending there
+func (s *MTSService) deliver(msg interface{}) (*ConnectionState, error) {
+	s.connMu.RLock()
+	activeConn := s.activeConn
+	s.connMu.RUnlock()
+
+	if activeConn == nil || atomic.LoadInt32(&s.connected) != 1 {
+		return nil, ErrNotConnected
+	}
+
+	// Increment pending responses counter
+	atomic.AddInt32(&activeConn.pendingResponses, 1)
+
+	if err := s.send(msg); err != nil {
+		atomic.AddInt32(&activeConn.pendingResponses, -1)
+		return nil, err
+	}
+	return activeConn, nil
+}
+
+// dispatch sends a message immediately when connected. While disconnected, and
+// if the offline queue is enabled, it holds the message for up to ttl and
+// returns once the message has been flushed after reconnect or has expired.
+func (s *MTSService) dispatch(msg interface{}, label string, ttl time.Duration) (*ConnectionState, error) {
+	// Go straight to the wire only if nothing is waiting, so flushes keep their order
+	if s.queue == nil || (s.IsConnected() && s.queue.len() == 0) {
+		return s.deliver(msg)
+	}
+	if ttl <= 0 {
+		return nil, ErrNotConnected
+	}
+
+	item := &queuedMessage{
+		msg:       msg,
+		label:     label,
+		expiresAt: s.clock.Now().Add(ttl),
+		done:      make(chan sendResult, 1),
+	}
+	if err := s.queue.push(item); err != nil {
+		return nil, err
+	}
+	log.Printf("MTS unavailable, queued %s (TTL %v, queue length %d)", label, ttl, s.queue.len())
+
+	// The connection may have come back between the check and the push
+	if s.IsConnected() {
+		go s.flushQueue()
+	}
+
+	expired, stop := s.clock.NewTimer(ttl)
+	defer stop()
+
+	select {
+	case res := <-item.done:
+		return res.conn, res.err
+	case <-expired:
+		if s.queue.withdraw(item) {
+			log.Printf("Queued %s expired after %v without reconnect", label, ttl)
+			return nil, ErrQueueTTLExpired
+		}
+		// A flush holds the message and answers it: sent, or expired
+		// instead of being re-queued
+		select {
+		case res := <-item.done:
+			return res.conn, res.err
+		case <-s.ctx.Done():
+			return nil, errors.New("service closed")
+		}
+	case <-s.ctx.Done():
+		s.queue.remove(item)
+		return nil, errors.New("service closed")
+	}
+}
+
+// flushQueue sends queued messages in submission order after a (re)connect
+func (s *MTSService) flushQueue() {
+	if s.queue == nil {
+		return
+	}
+
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	items := s.queue.drain()
+	if len(items) == 0 {
+		return
+	}
+	log.Printf("Flushing %d queued MTS request(s)", len(items))
+
+	for i, item := range items {
+		if s.clock.Now().After(item.expiresAt) {
+			log.Printf("Dropping queued %s: TTL expired before flush", item.label)
+			item.done <- sendResult{err: ErrQueueTTLExpired}
+			continue
+		}
+
+		conn, err := s.deliver(item.msg)
+		if err == ErrNotConnected {
+			// Lost the connection again; keep the rest for the next flush
+			withdrawn := s.queue.requeue(items[i:])
+			for _, expired := range withdrawn {
+				log.Printf("Dropping queued %s: TTL expired during flush", expired.label)
+				expired.done <- sendResult{err: ErrQueueTTLExpired}
+			}
+			log.Printf("Connection lost during flush, %d request(s) re-queued", len(items)-i-len(withdrawn))
+			return
+		}
+		item.done <- sendResult{conn: conn, err: err}
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	c       chan time.Time
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	return t.c, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		active := !t.stopped
+		t.stopped = true
+		return active
+	}
+}
+
+// Advance moves the clock and fires the timers that are due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		if !t.stopped && !t.at.After(c.now) {
+			t.stopped = true
+			t.c <- c.now
+		}
+	}
+}
+
+// active counts the timers that are neither stopped nor fired
+func (c *fakeClock) active() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+// fakeSender records the messages written to the connection. onSend, if
+// set, runs for every message and its error is returned.
+type fakeSender struct {
+	mu     sync.Mutex
+	sent   []interface{}
+	onSend func(msg interface{}) error
+}
+
+func (f *fakeSender) send(msg interface{}) error {
+	f.mu.Lock()
+	onSend := f.onSend
+	f.mu.Unlock()
+	if onSend != nil {
+		if err := onSend(msg); err != nil {
+			return err
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func (f *fakeSender) messages() []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]interface{}{}, f.sent...)
+}
+
+type queueEnv struct {
+	s      *MTSService
+	clock  *fakeClock
+	sender *fakeSender
+	items  map[string]*queuedMessage // Queued messages by content
+}
+
+func newQueueEnv(capacity int) *queueEnv {
+	ctx, cancel := context.WithCancel(context.Background())
+	env := &queueEnv{clock: newFakeClock(), sender: &fakeSender{}, items: make(map[string]*queuedMessage)}
+	env.s = &MTSService{
+		activeConn: &ConnectionState{id: "conn-test"},
+		clock:      env.clock,
+		send:       env.sender.send,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	if capacity > 0 {
+		env.s.queue = newOutboundQueue(capacity)
+	}
+	return env
+}
+
+func (env *queueEnv) setConnected(connected bool) {
+	if connected {
+		atomic.StoreInt32(&env.s.connected, 1)
+	} else {
+		atomic.StoreInt32(&env.s.connected, 0)
+	}
+}
+
+// queue dispatches msg in the background once the previous ones are queued,
+// so submission order is deterministic
+func (env *queueEnv) queue(t *testing.T, msg string, ttl time.Duration) <-chan sendResult {
+	t.Helper()
+	queued := env.s.QueueLength()
+	result := make(chan sendResult, 1)
+	go func() {
+		conn, err := env.s.dispatch(msg, msg, ttl)
+		result <- sendResult{conn: conn, err: err}
+	}()
+	waitFor(t, fmt.Sprintf("%s to be queued", msg), func() bool {
+		return env.s.QueueLength() == queued+1 && env.clock.active() == queued+1
+	})
+	env.s.queue.mu.Lock()
+	env.items[msg] = env.s.queue.items[queued]
+	env.s.queue.mu.Unlock()
+	return result
+}
+
+// withdrawn reports whether a queued message was marked withdrawn
+func (env *queueEnv) withdrawn(msg string) bool {
+	env.s.queue.mu.Lock()
+	defer env.s.queue.mu.Unlock()
+	return env.items[msg].withdrawn
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func result(t *testing.T, ch <-chan sendResult) sendResult {
+	t.Helper()
+	select {
+	case res := <-ch:
+		return res
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for dispatch to return")
+		return sendResult{}
+	}
+}
+
+func TestOfflineQueue(t *testing.T) {
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name     string
+		capacity int // Offline queue size, 3 when unset
+		run      func(t *testing.T, env *queueEnv)
+	}{
+		{
+			name: "connected sends immediately",
+			run: func(t *testing.T, env *queueEnv) {
+				env.setConnected(true)
+				conn, err := env.s.dispatch("a", "a", time.Minute)
+				if err != nil || conn != env.s.activeConn {
+					t.Fatalf("Expected a send on the active connection, got %v, %v", conn, err)
+				}
+				if sent := env.sender.messages(); len(sent) != 1 || env.s.QueueLength() != 0 {
+					t.Errorf("Expected one message sent and none queued, got %v and %d", sent, env.s.QueueLength())
+				}
+			},
+		},
+		{
+			name: "disconnected with a zero TTL is not queued",
+			run: func(t *testing.T, env *queueEnv) {
+				if _, err := env.s.dispatch("a", "a", 0); err != ErrNotConnected {
+					t.Errorf("Expected ErrNotConnected, got %v", err)
+				}
+			},
+		},
+		{
+			name:     "full queue rejects",
+			capacity: 2,
+			run: func(t *testing.T, env *queueEnv) {
+				env.queue(t, "a", time.Minute)
+				env.queue(t, "b", time.Minute)
+				if _, err := env.s.dispatch("c", "c", time.Minute); err != ErrQueueFull {
+					t.Errorf("Expected ErrQueueFull, got %v", err)
+				}
+			},
+		},
+		{
+			name: "TTL expires without reconnect",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+				b := env.queue(t, "b", 2*time.Minute)
+
+				env.clock.Advance(time.Minute)
+				if res := result(t, a); res.err != ErrQueueTTLExpired {
+					t.Errorf("Expected a to expire, got %v", res.err)
+				}
+				if env.s.QueueLength() != 1 {
+					t.Errorf("Expected b to stay queued, got length %d", env.s.QueueLength())
+				}
+
+				env.clock.Advance(time.Minute)
+				if res := result(t, b); res.err != ErrQueueTTLExpired {
+					t.Errorf("Expected b to expire, got %v", res.err)
+				}
+				if len(env.sender.messages()) != 0 {
+					t.Errorf("Expected nothing sent, got %v", env.sender.messages())
+				}
+			},
+		},
+		{
+			name: "flush on reconnect sends in order",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+				b := env.queue(t, "b", time.Minute)
+
+				env.setConnected(true)
+				env.s.flushQueue()
+				for _, ch := range []<-chan sendResult{a, b} {
+					if res := result(t, ch); res.err != nil || res.conn != env.s.activeConn {
+						t.Errorf("Expected a send on the active connection, got %v, %v", res.conn, res.err)
+					}
+				}
+				if sent := env.sender.messages(); len(sent) != 2 || sent[0] != "a" || sent[1] != "b" {
+					t.Errorf("Expected a then b, got %v", sent)
+				}
+			},
+		},
+		{
+			name: "flush drops messages whose TTL elapsed",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+
+				// Pass a's TTL without firing its timer, as if the flush got there first
+				env.clock.mu.Lock()
+				env.clock.now = env.clock.now.Add(time.Minute + time.Second)
+				env.clock.mu.Unlock()
+
+				env.setConnected(true)
+				env.s.flushQueue()
+				if res := result(t, a); res.err != ErrQueueTTLExpired {
+					t.Errorf("Expected a to expire, got %v", res.err)
+				}
+				if len(env.sender.messages()) != 0 {
+					t.Errorf("Expected nothing sent, got %v", env.sender.messages())
+				}
+			},
+		},
+		{
+			name: "send errors reach the caller",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+				env.sender.onSend = func(interface{}) error { return errWrite }
+
+				env.setConnected(true)
+				env.s.flushQueue()
+				if res := result(t, a); res.err != errWrite {
+					t.Errorf("Expected the write error, got %v", res.err)
+				}
+				if env.s.QueueLength() != 0 {
+					t.Errorf("Expected a failed send not to be re-queued, got length %d", env.s.QueueLength())
+				}
+			},
+		},
+		{
+			name: "connection lost during flush re-queues the rest",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+				b := env.queue(t, "b", time.Minute)
+				c := env.queue(t, "c", time.Minute)
+
+				env.sender.onSend = func(interface{}) error {
+					env.setConnected(false)
+					return nil
+				}
+				env.setConnected(true)
+				env.s.flushQueue()
+				if res := result(t, a); res.err != nil {
+					t.Fatalf("Expected a to be sent, got %v", res.err)
+				}
+				if env.s.QueueLength() != 2 {
+					t.Fatalf("Expected b and c to be re-queued, got length %d", env.s.QueueLength())
+				}
+
+				env.sender.onSend = nil
+				env.setConnected(true)
+				env.s.flushQueue()
+				for _, ch := range []<-chan sendResult{b, c} {
+					if res := result(t, ch); res.err != nil {
+						t.Errorf("Expected a send after the second reconnect, got %v", res.err)
+					}
+				}
+				if sent := env.sender.messages(); len(sent) != 3 || sent[1] != "b" || sent[2] != "c" {
+					t.Errorf("Expected a, b, c, got %v", sent)
+				}
+			},
+		},
+		{
+			name: "TTL elapsing during a flush is answered by the flush",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Hour)
+				b := env.queue(t, "b", time.Hour)
+				c := env.queue(t, "c", time.Minute)
+
+				// Hold the flush on a while c's TTL elapses, then drop the connection
+				sending, release := make(chan struct{}), make(chan struct{})
+				env.sender.onSend = func(interface{}) error {
+					close(sending)
+					<-release
+					env.setConnected(false)
+					return nil
+				}
+				env.setConnected(true)
+				flushed := make(chan struct{})
+				go func() {
+					env.s.flushQueue()
+					close(flushed)
+				}()
+				<-sending
+
+				env.clock.Advance(time.Minute)
+				waitFor(t, "c to be withdrawn", func() bool { return env.withdrawn("c") })
+				close(release)
+				<-flushed
+
+				if res := result(t, a); res.err != nil {
+					t.Errorf("Expected a to be sent, got %v", res.err)
+				}
+				if res := result(t, c); res.err != ErrQueueTTLExpired {
+					t.Errorf("Expected c to expire rather than be re-queued, got %v", res.err)
+				}
+				if env.s.QueueLength() != 1 {
+					t.Errorf("Expected only b to be re-queued, got length %d", env.s.QueueLength())
+				}
+				env.s.cancel()
+				if res := result(t, b); res.err == nil {
+					t.Error("Expected b to fail when the service closes")
+				}
+			},
+		},
+		{
+			name: "closing the service withdraws queued messages",
+			run: func(t *testing.T, env *queueEnv) {
+				a := env.queue(t, "a", time.Minute)
+				env.s.cancel()
+				if res := result(t, a); res.err == nil {
+					t.Error("Expected an error when the service closes")
+				}
+				if env.s.QueueLength() != 0 {
+					t.Errorf("Expected an empty queue, got length %d", env.s.QueueLength())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity := tt.capacity
+			if capacity == 0 {
+				capacity = 3
+			}
+			env := newQueueEnv(capacity)
+			defer env.s.cancel()
+			tt.run(t, env)
+		})
+	}
+
+	t.Run("disabled queue fails fast", func(t *testing.T) {
+		env := newQueueEnv(0)
+		defer env.s.cancel()
+		if _, err := env.s.dispatch("a", "a", time.Minute); err != ErrNotConnected {
+			t.Errorf("Expected ErrNotConnected, got %v", err)
+		}
+	})
+}
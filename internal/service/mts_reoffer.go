@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gdsZyy/mts-service/internal/models"
+)
+
+var (
+	// ErrReofferNotFound is returned when no offer is held for a ticket
+	ErrReofferNotFound = errors.New("no alternative stake offer for this ticket")
+	// ErrReofferExpired is returned when an offer is accepted after its expiry
+	ErrReofferExpired = errors.New("alternative stake offer has expired")
+)
+
+// RecordReoffer keeps the alternative stakes of a rejected ticket so they can
+// be accepted until the configured expiry. It returns nil when MTS offered none.
+func (s *MTSService) RecordReoffer(ticket *models.TicketRequest, response *models.TicketResponse) *models.Reoffer {
+	reoffer, err := models.NewReoffer(ticket, response, time.Now().Add(s.cfg.ReofferTTL))
+	if err != nil {
+		log.Printf("Ignoring alternative stake for ticket %s: %v", ticket.Content.TicketID, err)
+		return nil
+	}
+	if reoffer == nil {
+		return nil
+	}
+
+	s.reofferMu.Lock()
+	defer s.reofferMu.Unlock()
+	now := time.Now()
+	for id, held := range s.reoffers {
+		if held.Expired(now) {
+			delete(s.reoffers, id)
+		}
+	}
+	s.reoffers[reoffer.TicketID] = reoffer
+	return reoffer
+}
+
+// AcceptReoffer takes the offer held for a rejected ticket and returns the
+// ticket to place with the offered stakes. check, if not nil, vets that
+// ticket first; when it fails the offer is kept and its error returned. An
+// offer can be accepted once.
+func (s *MTSService) AcceptReoffer(ticketID, correlationID string, check func(*models.TicketRequest) error) (*models.Reoffer, *models.TicketRequest, error) {
+	s.reofferMu.Lock()
+	defer s.reofferMu.Unlock()
+
+	reoffer, ok := s.reoffers[ticketID]
+	if !ok {
+		return nil, nil, ErrReofferNotFound
+	}
+	if reoffer.Expired(time.Now()) {
+		delete(s.reoffers, ticketID)
+		return nil, nil, fmt.Errorf("%w at %s", ErrReofferExpired, reoffer.ExpiresAt.Format(time.RFC3339))
+	}
+	ticket := reoffer.Accept(correlationID)
+	if check != nil {
+		if err := check(ticket); err != nil {
+			return nil, nil, err
+		}
+	}
+	delete(s.reoffers, ticketID)
+	return reoffer, ticket, nil
+}
@@ -0,0 +1,117 @@
+package uof
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Specifier is one key=value pair of a market's specifiers
+type Specifier struct {
+	Key   string
+	Value string
+}
+
+// Specifiers are the specifiers of a market, e.g. "hcp=1:0|total=2.5"
+type Specifiers []Specifier
+
+var specifierKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ParseSpecifiers parses "key=value|key=value". A backslash escapes "|", "="
+// or itself within a value. Keys must be unique; the result is in canonical
+// order, sorted by key. An empty string has no specifiers.
+func ParseSpecifiers(s string) (Specifiers, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var specs Specifiers
+	seen := make(map[string]bool)
+	for _, pair := range splitUnescaped(s, '|') {
+		kv := splitUnescaped(pair, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid specifiers %q: %q is not key=value", s, pair)
+		}
+		key := strings.TrimSpace(kv[0])
+		value := unescape(strings.TrimSpace(kv[1]))
+		if !specifierKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid specifiers %q: invalid key %q", s, key)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("invalid specifiers %q: %s has no value", s, key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("invalid specifiers %q: %s is repeated", s, key)
+		}
+		seen[key] = true
+		specs = append(specs, Specifier{Key: key, Value: value})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
+	return specs, nil
+}
+
+// Get returns the value of a key
+func (s Specifiers) Get(key string) (string, bool) {
+	for _, spec := range s {
+		if spec.Key == key {
+			return spec.Value, true
+		}
+	}
+	return "", false
+}
+
+// String renders the specifiers canonically, escaping "|", "=" and "\" in values
+func (s Specifiers) String() string {
+	pairs := make([]string, len(s))
+	for i, spec := range s {
+		pairs[i] = spec.Key + "=" + escape(spec.Value)
+	}
+	return strings.Join(pairs, "|")
+}
+
+// CanonicalSpecifiers returns the canonical form of a specifier string, or s
+// unchanged if it does not parse
+func CanonicalSpecifiers(s string) string {
+	specs, err := ParseSpecifiers(s)
+	if err != nil {
+		return s
+	}
+	return specs.String()
+}
+
+// splitUnescaped splits s at every sep not preceded by a backslash, keeping
+// escapes in the parts
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `=`, `\=`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,86 @@
+package uof
+
+import "testing"
+
+func TestParseURN(t *testing.T) {
+	u, err := ParseURN("SR:Match:12345")
+	if err != nil {
+		t.Fatalf("ParseURN failed: %v", err)
+	}
+	if u.Prefix != "sr" || u.Type != "match" || u.ID != "12345" || u.String() != "sr:match:12345" {
+		t.Errorf("Unexpected URN %+v", u)
+	}
+	if got := CanonicalURN("od:simple_tournament:7"); got != "od:simple_tournament:7" {
+		t.Errorf("Expected a custom prefix to be kept, got %q", got)
+	}
+
+	for _, invalid := range []string{"", "12345", "sr:match", "sr:match:", "sr:match:1:2", "s r:match:1", "sr::1"} {
+		if _, err := ParseURN(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestParseSpecifiers(t *testing.T) {
+	tests := []struct {
+		value     string
+		canonical string
+	}{
+		{"", ""},
+		{"total=2.5", "total=2.5"},
+		{"total=2.5|hcp=1:0", "hcp=1:0|total=2.5"},
+		{" variant = sr:exact_goals:6+ ", "variant=sr:exact_goals:6+"},
+		{`name=a\|b|alias=x\=y`, `alias=x\=y|name=a\|b`},
+		{`path=c:\\d`, `path=c:\\d`},
+	}
+	for _, tt := range tests {
+		specs, err := ParseSpecifiers(tt.value)
+		if err != nil {
+			t.Errorf("ParseSpecifiers(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got := specs.String(); got != tt.canonical {
+			t.Errorf("ParseSpecifiers(%q) = %q, expected %q", tt.value, got, tt.canonical)
+		}
+	}
+
+	specs, _ := ParseSpecifiers(`name=a\|b`)
+	if v, ok := specs.Get("name"); !ok || v != "a|b" {
+		t.Errorf("Expected the unescaped value a|b, got %q", v)
+	}
+
+	for _, invalid := range []string{"total", "total=", "=2.5", "total=2.5|total=3.5", "total=2.5||hcp=1", "to-tal=1", "a=b=c"} {
+		if _, err := ParseSpecifiers(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+		if got := CanonicalSpecifiers(invalid); got != invalid {
+			t.Errorf("Expected %q to be left unchanged, got %q", invalid, got)
+		}
+	}
+}
+
+func TestValidateOutcomeID(t *testing.T) {
+	valid := map[string]string{
+		"1":                    "1",
+		"0012":                 "12",
+		"0":                    "0",
+		"pre:outcometext:9919": "pre:outcometext:9919",
+		"PRE:OutcomeText:9919": "pre:outcometext:9919",
+		"sr:player:123":        "sr:player:123",
+		"sr:exact_goals:4+:13": "sr:exact_goals:4+:13",
+	}
+	for id, canonical := range valid {
+		if err := ValidateOutcomeID(id); err != nil {
+			t.Errorf("ValidateOutcomeID(%q) failed: %v", id, err)
+		}
+		if got := CanonicalOutcomeID(id); got != canonical {
+			t.Errorf("CanonicalOutcomeID(%q) = %q, expected %q", id, got, canonical)
+		}
+	}
+
+	for _, invalid := range []string{"", "home", "-1", "1.5", "sr:player", "pre:outcometext:abc", "pre:other:1", "pre:outcometext:1:2", "sr:player:"} {
+		if err := ValidateOutcomeID(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
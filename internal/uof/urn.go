@@ -0,0 +1,111 @@
+// Package uof parses the identifiers of the Unified Odds Feed: URNs such as
+// "sr:match:123", market specifiers such as "hcp=1:0|total=2.5" and outcome
+// IDs such as "pre:outcometext:9919". Canonical forms let selections that
+// name the same outcome differently compare equal.
+package uof
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// URN is a UOF resource name: prefix "sr", type "match" and ID "123" in
+// "sr:match:123"
+type URN struct {
+	Prefix string
+	Type   string
+	ID     string
+}
+
+var (
+	urnPrefixPattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	urnTypePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	urnIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+	digitsPattern    = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ParseURN parses "prefix:type:id". Prefix and type are case-insensitive and
+// returned in lower case; the ID may not contain colons.
+func ParseURN(s string) (URN, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return URN{}, fmt.Errorf("invalid URN %q: expected prefix:type:id", s)
+	}
+	u := URN{Prefix: strings.ToLower(parts[0]), Type: strings.ToLower(parts[1]), ID: parts[2]}
+	if !urnPrefixPattern.MatchString(u.Prefix) {
+		return URN{}, fmt.Errorf("invalid URN %q: invalid prefix %q", s, parts[0])
+	}
+	if !urnTypePattern.MatchString(u.Type) {
+		return URN{}, fmt.Errorf("invalid URN %q: invalid type %q", s, parts[1])
+	}
+	if !urnIDPattern.MatchString(u.ID) {
+		return URN{}, fmt.Errorf("invalid URN %q: invalid ID %q", s, parts[2])
+	}
+	return u, nil
+}
+
+func (u URN) String() string {
+	return u.Prefix + ":" + u.Type + ":" + u.ID
+}
+
+// CanonicalURN returns the canonical form of a URN, or s unchanged if it is
+// not one
+func CanonicalURN(s string) string {
+	u, err := ParseURN(s)
+	if err != nil {
+		return s
+	}
+	return u.String()
+}
+
+// ValidateOutcomeID checks the formats an outcome ID can take: a number
+// ("1712"), pre-match outcome text ("pre:outcometext:9919"), a player or
+// competitor URN ("sr:player:123") or a variant outcome of several segments
+// ("sr:exact_goals:4+:13").
+func ValidateOutcomeID(id string) error {
+	_, err := parseOutcomeID(id)
+	return err
+}
+
+// CanonicalOutcomeID returns the canonical form of an outcome ID, or id
+// unchanged if it is not valid
+func CanonicalOutcomeID(id string) string {
+	canonical, err := parseOutcomeID(id)
+	if err != nil {
+		return id
+	}
+	return canonical
+}
+
+func parseOutcomeID(id string) (string, error) {
+	s := strings.TrimSpace(id)
+	if digitsPattern.MatchString(s) {
+		return strings.TrimLeft(s, "0") + zeroIfEmpty(s), nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 {
+		return "", fmt.Errorf("invalid outcome ID %q: expected a number or a URN", id)
+	}
+	parts[0], parts[1] = strings.ToLower(parts[0]), strings.ToLower(parts[1])
+	if !urnPrefixPattern.MatchString(parts[0]) || !urnTypePattern.MatchString(parts[1]) {
+		return "", fmt.Errorf("invalid outcome ID %q: invalid prefix or type", id)
+	}
+	for _, part := range parts[2:] {
+		if !urnIDPattern.MatchString(part) {
+			return "", fmt.Errorf("invalid outcome ID %q: invalid segment %q", id, part)
+		}
+	}
+	if parts[0] == "pre" && (parts[1] != "outcometext" || len(parts) != 3 || !digitsPattern.MatchString(parts[2])) {
+		return "", fmt.Errorf("invalid outcome ID %q: expected pre:outcometext:<number>", id)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// zeroIfEmpty keeps "0" from trimming to an empty string
+func zeroIfEmpty(digits string) string {
+	if strings.TrimLeft(digits, "0") == "" {
+		return "0"
+	}
+	return ""
+}
@@ -2,12 +2,17 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gdsZyy/mts-service/internal/config"
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/decimal"
 	"github.com/gdsZyy/mts-service/internal/models"
+	"github.com/gdsZyy/mts-service/internal/odds"
 	"github.com/gdsZyy/mts-service/internal/service"
 	"github.com/google/uuid"
 )
@@ -20,6 +25,11 @@ type BetProcessor struct {
 	
 	// Track pending tickets for status queries
 	pendingTickets map[string]string // ticketID -> userID
+	pendingMu      sync.Mutex
+
+	// Users that were offered an alternative stake, by rejected ticket ID
+	reofferedTo map[string]string
+	reofferMu   sync.Mutex
 }
 
 // NewBetProcessor creates a new BetProcessor
@@ -29,6 +39,7 @@ func NewBetProcessor(hub *Hub, mtsService *service.MTSService, cfg *config.Confi
 		mtsService:     mtsService,
 		cfg:            cfg,
 		pendingTickets: make(map[string]string),
+		reofferedTo:    make(map[string]string),
 	}
 }
 
@@ -36,6 +47,7 @@ func NewBetProcessor(hub *Hub, mtsService *service.MTSService, cfg *config.Confi
 func (bp *BetProcessor) Start() {
 	go bp.processBetRequests()
 	go bp.processStatusQueries()
+	go bp.processReofferAcceptances()
 }
 
 // processBetRequests handles incoming bet requests
@@ -52,6 +64,13 @@ func (bp *BetProcessor) processStatusQueries() {
 	}
 }
 
+// processReofferAcceptances handles accept_reoffer requests
+func (bp *BetProcessor) processReofferAcceptances() {
+	for acceptance := range bp.hub.reofferAcceptances {
+		go bp.handleReofferAcceptance(acceptance)
+	}
+}
+
 // handleBetRequest processes a single bet request
 func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	client := betReq.Client
@@ -60,6 +79,20 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	log.Printf("Processing bet request: requestID=%s, betType=%s, userID=%s", 
 		req.RequestID, req.BetType, client.userID)
 
+	// Odds in results are rendered in the client's preferred format
+	format, err := odds.ParseFormat(getStringValue(req.Payload, "oddsFormat"))
+	if err != nil {
+		client.SendError(req.RequestID, fmt.Sprintf("Invalid oddsFormat: %v", err), nil)
+		return
+	}
+
+	// Whether tickets rejected for changed odds are resubmitted at the new odds
+	policy, err := models.ParseOddsChangePolicy(getStringValue(req.Payload, "oddsChange"))
+	if err != nil {
+		client.SendError(req.RequestID, err.Error(), nil)
+		return
+	}
+
 	// Generate ticket ID(s)
 	var ticketIDs []string
 	var tickets []*models.TicketRequest
@@ -68,7 +101,7 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	case "single":
 		ticket, err := bp.buildSingleBet(req)
 		if err != nil {
-			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), nil)
+			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), buildErrorDetails(err))
 			return
 		}
 		tickets = append(tickets, ticket)
@@ -84,7 +117,7 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 		for _, betData := range bets {
 			ticket, err := bp.buildSingleBetFromPayload(betData)
 			if err != nil {
-				client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), nil)
+				client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), buildErrorDetails(err))
 				return
 			}
 			tickets = append(tickets, ticket)
@@ -94,7 +127,7 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	case "accumulator":
 		ticket, err := bp.buildAccumulatorBet(req)
 		if err != nil {
-			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), nil)
+			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), buildErrorDetails(err))
 			return
 		}
 		tickets = append(tickets, ticket)
@@ -103,7 +136,7 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	case "system":
 		ticket, err := bp.buildSystemBet(req)
 		if err != nil {
-			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), nil)
+			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), buildErrorDetails(err))
 			return
 		}
 		tickets = append(tickets, ticket)
@@ -112,7 +145,7 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 	case "banker":
 		ticket, err := bp.buildBankerBet(req)
 		if err != nil {
-			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), nil)
+			client.SendError(req.RequestID, fmt.Sprintf("Failed to build ticket: %v", err), buildErrorDetails(err))
 			return
 		}
 		tickets = append(tickets, ticket)
@@ -123,6 +156,18 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 		return
 	}
 
+	// Check stakes against the currency registry and the local limits
+	for _, ticket := range tickets {
+		if err := bp.cfg.Currencies.CheckTicket(ticket); err != nil {
+			client.SendError(req.RequestID, fmt.Sprintf("Invalid stake: %v", err), buildErrorDetails(err))
+			return
+		}
+		if err := bp.cfg.Limits.Check(ticket, bp.cfg.Currencies); err != nil {
+			client.SendError(req.RequestID, fmt.Sprintf("Limit exceeded: %v", err), buildErrorDetails(err))
+			return
+		}
+	}
+
 	// Send bet received confirmation
 	if len(ticketIDs) == 1 {
 		client.SendMessage(&BetReceivedResponse{
@@ -146,30 +191,30 @@ func (bp *BetProcessor) handleBetRequest(betReq *BetRequest) {
 
 	// Track pending tickets
 	for _, ticketID := range ticketIDs {
-		bp.pendingTickets[ticketID] = client.userID
+		bp.trackPending(ticketID, client.userID)
 	}
 
 	// Process tickets
 	if len(tickets) == 1 {
-		bp.processSingleTicket(client, req.RequestID, tickets[0])
+		bp.processSingleTicket(client, req.RequestID, tickets[0], format, policy)
 	} else {
-		bp.processMultipleTickets(client, req.RequestID, tickets)
+		bp.processMultipleTickets(client, req.RequestID, tickets, format, policy)
 	}
 }
 
 // processSingleTicket sends a single ticket to MTS and pushes result
-func (bp *BetProcessor) processSingleTicket(client *Client, requestID string, ticket *models.TicketRequest) {
+func (bp *BetProcessor) processSingleTicket(client *Client, requestID string, ticket *models.TicketRequest, format odds.Format, policy string) {
 	// Send to MTS
-	response, err := bp.mtsService.SendTicket(ticket)
+	ticket, response, err := bp.sendTicket(client, requestID, ticket, policy)
 	if err != nil {
 		client.SendError(requestID, fmt.Sprintf("Failed to send ticket: %v", err), nil)
-		delete(bp.pendingTickets, ticket.Content.TicketID)
+		bp.untrackPending(ticket.Content.TicketID)
 		return
 	}
 
 	// Convert response to map for details
 	details := make(map[string]interface{})
-	responseBytes, _ := json.Marshal(response)
+	responseBytes, _ := json.Marshal(response.WithOddsFormat(format))
 	json.Unmarshal(responseBytes, &details)
 
 	// Determine status
@@ -188,13 +233,17 @@ func (bp *BetProcessor) processSingleTicket(client *Client, requestID string, ti
 		TicketID:  ticket.Content.TicketID,
 		Status:    status,
 		Details:   details,
+		Issues:    models.InterpretResponse(ticket, response),
+		Currency:  bp.cfg.Currencies.Record(ticket, response),
+		Bets:      models.MapBetDetails(ticket, response),
 	})
+	bp.offerReoffer(client, requestID, ticket, response)
 
-	delete(bp.pendingTickets, ticket.Content.TicketID)
+	bp.untrackPending(ticket.Content.TicketID)
 }
 
 // processMultipleTickets sends multiple tickets to MTS and pushes partial/final results
-func (bp *BetProcessor) processMultipleTickets(client *Client, requestID string, tickets []*models.TicketRequest) {
+func (bp *BetProcessor) processMultipleTickets(client *Client, requestID string, tickets []*models.TicketRequest, format odds.Format, policy string) {
 	total := len(tickets)
 	completed := 0
 	accepted := 0
@@ -203,10 +252,12 @@ func (bp *BetProcessor) processMultipleTickets(client *Client, requestID string,
 
 	for _, ticket := range tickets {
 		// Send to MTS
-		response, err := bp.mtsService.SendTicket(ticket)
+		ticket, response, err := bp.sendTicket(client, requestID, ticket, policy)
 		
 		var details map[string]interface{}
 		var status string
+		var issues []models.Issue
+		var record *currency.TicketRecord
 		
 		if err != nil {
 			status = "rejected"
@@ -215,8 +266,10 @@ func (bp *BetProcessor) processMultipleTickets(client *Client, requestID string,
 			}
 			rejected++
 		} else {
-			responseBytes, _ := json.Marshal(response)
+			responseBytes, _ := json.Marshal(response.WithOddsFormat(format))
 			json.Unmarshal(responseBytes, &details)
+			issues = models.InterpretResponse(ticket, response)
+			record = bp.cfg.Currencies.Record(ticket, response)
 			
 			if response.Content.Status == "accepted" {
 				status = "accepted"
@@ -241,9 +294,15 @@ func (bp *BetProcessor) processMultipleTickets(client *Client, requestID string,
 			TicketID:  ticket.Content.TicketID,
 			Status:    status,
 			Details:   details,
+			Issues:    issues,
+			Currency:  record,
+			Bets:      models.MapBetDetails(ticket, response),
 		})
+		if err == nil {
+			bp.offerReoffer(client, requestID, ticket, response)
+		}
 
-		delete(bp.pendingTickets, ticket.Content.TicketID)
+		bp.untrackPending(ticket.Content.TicketID)
 	}
 
 	// Send final result with summary
@@ -271,7 +330,7 @@ func (bp *BetProcessor) handleStatusQuery(query *StatusQuery) {
 
 	// In a real implementation, you would query the ticket status from a database or cache
 	// For now, we'll just check if it's in pending tickets
-	_, isPending := bp.pendingTickets[req.TicketID]
+	isPending := bp.isPending(req.TicketID)
 	
 	status := "not_found"
 	if isPending {
@@ -288,6 +347,161 @@ func (bp *BetProcessor) handleStatusQuery(query *StatusQuery) {
 	})
 }
 
+// trackPending records a ticket sent to MTS for status queries
+func (bp *BetProcessor) trackPending(ticketID, userID string) {
+	bp.pendingMu.Lock()
+	bp.pendingTickets[ticketID] = userID
+	bp.pendingMu.Unlock()
+}
+
+// untrackPending forgets a ticket once MTS answered it
+func (bp *BetProcessor) untrackPending(ticketID string) {
+	bp.pendingMu.Lock()
+	delete(bp.pendingTickets, ticketID)
+	bp.pendingMu.Unlock()
+}
+
+// replacePending swaps a ticket for the one resubmitted in its place, in one
+// step so a status query never sees neither
+func (bp *BetProcessor) replacePending(oldID, newID, userID string) {
+	bp.pendingMu.Lock()
+	delete(bp.pendingTickets, oldID)
+	bp.pendingTickets[newID] = userID
+	bp.pendingMu.Unlock()
+}
+
+// isPending reports whether a ticket is still waiting for MTS
+func (bp *BetProcessor) isPending(ticketID string) bool {
+	bp.pendingMu.Lock()
+	defer bp.pendingMu.Unlock()
+	_, ok := bp.pendingTickets[ticketID]
+	return ok
+}
+
+// maxOddsResubmits limits how often one ticket is resubmitted for changed odds
+const maxOddsResubmits = 2
+
+// sendTicket sends a ticket to MTS. When MTS rejects it because odds moved,
+// the client receives bet_odds_changed and, if the policy accepts the new
+// odds, the ticket is resubmitted at those odds as "<ticketId>-odds<n>". The
+// last ticket sent is returned with its response.
+func (bp *BetProcessor) sendTicket(client *Client, requestID string, ticket *models.TicketRequest, policy string) (*models.TicketRequest, *models.TicketResponse, error) {
+	originalID := ticket.Content.TicketID
+	for attempt := 1; ; attempt++ {
+		response, err := bp.mtsService.SendTicket(ticket)
+		if err != nil {
+			return ticket, nil, err
+		}
+		changes := models.FindOddsChanges(ticket, response)
+		if len(changes) == 0 {
+			return ticket, response, nil
+		}
+
+		notice := &BetOddsChangedResponse{
+			BaseMessage: BaseMessage{
+				Type:      MessageTypeBetOddsChanged,
+				Timestamp: time.Now(),
+			},
+			RequestID: requestID,
+			TicketID:  ticket.Content.TicketID,
+			Policy:    policy,
+			Changes:   changes,
+		}
+		if attempt > maxOddsResubmits || !models.AllowsOddsChanges(policy, changes) {
+			client.SendMessage(notice)
+			return ticket, response, nil
+		}
+
+		next := models.ApplyOddsChanges(ticket, changes, fmt.Sprintf("%s-odds%d", originalID, attempt), uuid.New().String())
+		notice.Resubmitted, notice.NewTicketID = true, next.Content.TicketID
+		client.SendMessage(notice)
+		log.Printf("Resubmitting ticket %s as %s at changed odds (policy %s)", ticket.Content.TicketID, next.Content.TicketID, policy)
+
+		bp.replacePending(ticket.Content.TicketID, next.Content.TicketID, client.userID)
+		ticket = next
+	}
+}
+
+// offerReoffer pushes bet_reoffer when MTS offered a lower stake for a rejected ticket
+func (bp *BetProcessor) offerReoffer(client *Client, requestID string, ticket *models.TicketRequest, response *models.TicketResponse) {
+	reoffer := bp.mtsService.RecordReoffer(ticket, response)
+	if reoffer == nil {
+		return
+	}
+
+	bp.reofferMu.Lock()
+	bp.reofferedTo[reoffer.TicketID] = client.userID
+	bp.reofferMu.Unlock()
+
+	client.SendMessage(&BetReofferResponse{
+		BaseMessage: BaseMessage{
+			Type:      MessageTypeBetReoffer,
+			Timestamp: time.Now(),
+		},
+		RequestID: requestID,
+		Reoffer:   reoffer,
+	})
+}
+
+// handleReofferAcceptance places the ticket again with the offered stakes,
+// under a new ticket ID linked to the rejected one
+func (bp *BetProcessor) handleReofferAcceptance(acceptance *ReofferAcceptance) {
+	client := acceptance.Client
+	req := acceptance.Request
+
+	format, err := odds.ParseFormat(req.OddsFormat)
+	if err != nil {
+		client.SendError(req.RequestID, fmt.Sprintf("Invalid oddsFormat: %v", err), nil)
+		return
+	}
+
+	bp.reofferMu.Lock()
+	owner, ok := bp.reofferedTo[req.TicketID]
+	if ok && owner == client.userID {
+		delete(bp.reofferedTo, req.TicketID)
+	}
+	bp.reofferMu.Unlock()
+	if !ok || owner != client.userID {
+		client.SendError(req.RequestID, service.ErrReofferNotFound.Error(), nil)
+		return
+	}
+
+	_, ticket, err := bp.mtsService.AcceptReoffer(req.TicketID, uuid.New().String(), bp.checkTicket)
+	if err != nil {
+		if !errors.Is(err, service.ErrReofferNotFound) && !errors.Is(err, service.ErrReofferExpired) {
+			// The offer is still held; let the client try again
+			bp.reofferMu.Lock()
+			bp.reofferedTo[req.TicketID] = client.userID
+			bp.reofferMu.Unlock()
+		}
+		client.SendError(req.RequestID, fmt.Sprintf("Cannot accept alternative stake: %v", err), buildErrorDetails(err))
+		return
+	}
+
+	client.SendMessage(&BetReceivedResponse{
+		BaseMessage: BaseMessage{
+			Type:      MessageTypeBetReceived,
+			Timestamp: time.Now(),
+		},
+		RequestID: req.RequestID,
+		TicketID:  ticket.Content.TicketID,
+	})
+	bp.trackPending(ticket.Content.TicketID, client.userID)
+	bp.processSingleTicket(client, req.RequestID, ticket, format, models.OddsChangeNone)
+}
+
+// checkTicket checks the stakes of a built ticket against the currency
+// registry and the local limits
+func (bp *BetProcessor) checkTicket(ticket *models.TicketRequest) error {
+	if err := bp.cfg.Currencies.CheckTicket(ticket); err != nil {
+		return fmt.Errorf("invalid stake: %w", err)
+	}
+	if err := bp.cfg.Limits.Check(ticket, bp.cfg.Currencies); err != nil {
+		return fmt.Errorf("limit exceeded: %w", err)
+	}
+	return nil
+}
+
 // Helper functions to build tickets from WebSocket requests
 
 func (bp *BetProcessor) buildSingleBet(req *PlaceBetRequest) (*models.TicketRequest, error) {
@@ -300,18 +514,20 @@ func (bp *BetProcessor) buildSingleBet(req *PlaceBetRequest) (*models.TicketRequ
 		return nil, fmt.Errorf("invalid selection data")
 	}
 	
-	stakeData, ok := req.Payload["stake"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid stake data")
+	stakes, err := convertStakes(req.Payload["stake"], "total")
+	if err != nil {
+		return nil, err
 	}
 
-	selection := convertSelection(selectionData)
-	stake := convertStake(stakeData)
+	selection, err := convertSelection(selectionData)
+	if err != nil {
+		return nil, err
+	}
 
-	builder.AddSingleBet(selection, stake)
+	builder.AddSingleBet(selection, stakes...)
 	builder.SetContext(getDefaultContext(bp.cfg))
 
-	return builder.Build(uuid.New().String()), nil
+	return builder.Build(uuid.New().String())
 }
 
 func (bp *BetProcessor) buildSingleBetFromPayload(betData interface{}) (*models.TicketRequest, error) {
@@ -328,18 +544,20 @@ func (bp *BetProcessor) buildSingleBetFromPayload(betData interface{}) (*models.
 		return nil, fmt.Errorf("invalid selection data")
 	}
 	
-	stakeData, ok := betMap["stake"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid stake data")
+	stakes, err := convertStakes(betMap["stake"], "total")
+	if err != nil {
+		return nil, err
 	}
 
-	selection := convertSelection(selectionData)
-	stake := convertStake(stakeData)
+	selection, err := convertSelection(selectionData)
+	if err != nil {
+		return nil, err
+	}
 
-	builder.AddSingleBet(selection, stake)
+	builder.AddSingleBet(selection, stakes...)
 	builder.SetContext(getDefaultContext(bp.cfg))
 
-	return builder.Build(uuid.New().String()), nil
+	return builder.Build(uuid.New().String())
 }
 
 func (bp *BetProcessor) buildAccumulatorBet(req *PlaceBetRequest) (*models.TicketRequest, error) {
@@ -351,26 +569,21 @@ func (bp *BetProcessor) buildAccumulatorBet(req *PlaceBetRequest) (*models.Ticke
 		return nil, fmt.Errorf("invalid selections data")
 	}
 	
-	stakeData, ok := req.Payload["stake"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid stake data")
+	stakes, err := convertStakes(req.Payload["stake"], "total")
+	if err != nil {
+		return nil, err
 	}
 
-	var selections []models.Selection
-	for _, selData := range selectionsData {
-		selMap, ok := selData.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		selections = append(selections, convertSelection(selMap))
+	selections, err := convertSelections(selectionsData)
+	if err != nil {
+		return nil, err
 	}
 
-	stake := convertStake(stakeData)
 
-	builder.AddAccumulatorBet(selections, stake)
+	builder.AddAccumulatorBet(selections, stakes...)
 	builder.SetContext(getDefaultContext(bp.cfg))
 
-	return builder.Build(uuid.New().String()), nil
+	return builder.Build(uuid.New().String())
 }
 
 func (bp *BetProcessor) buildSystemBet(req *PlaceBetRequest) (*models.TicketRequest, error) {
@@ -382,31 +595,29 @@ func (bp *BetProcessor) buildSystemBet(req *PlaceBetRequest) (*models.TicketRequ
 		return nil, fmt.Errorf("invalid selections data")
 	}
 	
-	stakeData, ok := req.Payload["stake"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid stake data")
+	stakes, err := convertStakes(req.Payload["stake"], "unit")
+	if err != nil {
+		return nil, err
 	}
 
-	systemSize, ok := req.Payload["systemSize"].(float64)
-	if !ok {
-		return nil, fmt.Errorf("invalid systemSize")
+	selections, err := convertSelections(selectionsData)
+	if err != nil {
+		return nil, err
 	}
 
-	var selections []models.Selection
-	for _, selData := range selectionsData {
-		selMap, ok := selData.(map[string]interface{})
+	// "notation" (e.g. "2,3/5", "B1+2/4" or "yankee") replaces systemSize
+	if notation := getStringValue(req.Payload, "notation"); notation != "" {
+		builder.AddNotationBet(notation, selections, stakes...)
+	} else {
+		systemSize, ok := getIntValue(req.Payload, "systemSize")
 		if !ok {
-			continue
+			return nil, fmt.Errorf("invalid systemSize")
 		}
-		selections = append(selections, convertSelection(selMap))
+		builder.AddSystemBet([]int{systemSize}, selections, stakes...)
 	}
-
-	stake := convertStake(stakeData)
-
-	builder.AddSystemBet([]int{int(systemSize)}, selections, stake)
 	builder.SetContext(getDefaultContext(bp.cfg))
 
-	return builder.Build(uuid.New().String()), nil
+	return builder.Build(uuid.New().String())
 }
 
 func (bp *BetProcessor) buildBankerBet(req *PlaceBetRequest) (*models.TicketRequest, error) {
@@ -423,63 +634,155 @@ func (bp *BetProcessor) buildBankerBet(req *PlaceBetRequest) (*models.TicketRequ
 		return nil, fmt.Errorf("invalid bankerSelections data")
 	}
 	
-	stakeData, ok := req.Payload["stake"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid stake data")
+	stakes, err := convertStakes(req.Payload["stake"], "unit")
+	if err != nil {
+		return nil, err
 	}
 
-	systemSize, ok := req.Payload["systemSize"].(float64)
-	if !ok {
-		return nil, fmt.Errorf("invalid systemSize")
+	selections, err := convertSelections(selectionsData)
+	if err != nil {
+		return nil, err
 	}
 
-	var selections []models.Selection
-	for _, selData := range selectionsData {
-		selMap, ok := selData.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		selections = append(selections, convertSelection(selMap))
+	bankerSelections, err := convertSelections(bankerSelectionsData)
+	if err != nil {
+		return nil, err
 	}
 
-	var bankerSelections []models.Selection
-	for _, selData := range bankerSelectionsData {
-		selMap, ok := selData.(map[string]interface{})
+	// "notation" (e.g. "B1+2/4") replaces systemSize; bankers come first
+	if notation := getStringValue(req.Payload, "notation"); notation != "" {
+		builder.AddNotationBet(notation, append(bankerSelections, selections...), stakes...)
+	} else {
+		systemSize, ok := getIntValue(req.Payload, "systemSize")
 		if !ok {
-			continue
+			return nil, fmt.Errorf("invalid systemSize")
 		}
-		bankerSelections = append(bankerSelections, convertSelection(selMap))
+		builder.AddBankerSystemBet(bankerSelections, []int{systemSize}, selections, stakes...)
 	}
-
-	stake := convertStake(stakeData)
-
-	builder.AddBankerSystemBet(bankerSelections, []int{int(systemSize)}, selections, stake)
 	builder.SetContext(getDefaultContext(bp.cfg))
 
-	return builder.Build(uuid.New().String()), nil
+	return builder.Build(uuid.New().String())
 }
 
 // Helper functions
 
-func convertSelection(data map[string]interface{}) models.Selection {
-	return models.Selection{
-		Type:      "uf",
-		ProductID: "3",
-		EventID:   getStringValue(data, "eventId"),
-		MarketID:  getStringValue(data, "marketId"),
-		OutcomeID: getStringValue(data, "outcomeId"),
-		Odds: &models.Odds{
-			Type:  "decimal",
-			Value: getStringValue(data, "odds"),
-		},
+// buildErrorDetails exposes builder validation errors field by field, so
+// clients can highlight e.g. correlated selections
+func buildErrorDetails(err error) map[string]interface{} {
+	var errs models.BuilderErrors
+	if errors.As(err, &errs) {
+		return map[string]interface{}{"errors": errs}
 	}
+	return nil
 }
 
-func convertStake(data map[string]interface{}) models.Stake {
+// convertSelection reads a selection payload; odds may be given in any
+// format named by "oddsFormat" and are converted to decimal for MTS.
+// "type" selects uf (default, product "3" unless given), external or
+// uf-custom-bet, whose markets are read from "legs".
+func convertSelection(data map[string]interface{}) (models.Selection, error) {
+	value := getDecimalValue(data, "odds")
+	if format := getStringValue(data, "oddsFormat"); format != "" {
+		parsed, err := odds.ParseFormat(format)
+		if err != nil {
+			return models.Selection{}, err
+		}
+		if value, err = odds.ToDecimal(value, parsed); err != nil {
+			return models.Selection{}, fmt.Errorf("odds: %w", err)
+		}
+	}
+
+	productID := getStringValue(data, "productId")
+	eventID := getStringValue(data, "eventId")
+	marketID := getStringValue(data, "marketId")
+	outcomeID := getStringValue(data, "outcomeId")
+	specifiers := getStringValue(data, "specifiers")
+
+	switch selType := getStringValue(data, "type"); selType {
+	case "", models.SelectionTypeUF:
+		if productID == "" {
+			productID = "3"
+		}
+		return models.NewSelection(productID, eventID, marketID, outcomeID, value, specifiers), nil
+	case models.SelectionTypeExternal:
+		return models.NewExternalSelection(eventID, marketID, outcomeID, value, specifiers), nil
+	case models.SelectionTypeCustomBet:
+		if productID == "" {
+			productID = "3"
+		}
+		legsData, _ := data["legs"].([]interface{})
+		legs := make([]models.Selection, 0, len(legsData))
+		for i, legData := range legsData {
+			legMap, ok := legData.(map[string]interface{})
+			if !ok {
+				return models.Selection{}, fmt.Errorf("legs[%d]: invalid leg", i)
+			}
+			legs = append(legs, models.NewCustomBetLeg(
+				getStringValue(legMap, "marketId"),
+				getStringValue(legMap, "outcomeId"),
+				getStringValue(legMap, "specifiers"),
+			))
+		}
+		return models.NewCustomBetSelection(productID, eventID, value, legs...), nil
+	default:
+		return models.Selection{}, fmt.Errorf("unknown selection type %q", selType)
+	}
+}
+
+// convertSelections converts a list of selection payloads, skipping non-objects
+func convertSelections(data []interface{}) ([]models.Selection, error) {
+	var selections []models.Selection
+	for i, selData := range data {
+		selMap, ok := selData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		selection, err := convertSelection(selMap)
+		if err != nil {
+			return nil, fmt.Errorf("selection[%d]: %w", i, err)
+		}
+		selections = append(selections, selection)
+	}
+	return selections, nil
+}
+
+// convertStakes reads a stake payload given as one object or an array of
+// objects, e.g. part cash and part free bet. Entries without a mode get
+// defaultMode, since clients have never had to send one.
+func convertStakes(data interface{}, defaultMode string) ([]models.Stake, error) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return []models.Stake{convertStake(v, defaultMode)}, nil
+	case []interface{}:
+		stakes := make([]models.Stake, 0, len(v))
+		for i, item := range v {
+			stakeMap, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("stake[%d]: invalid stake data", i)
+			}
+			stakes = append(stakes, convertStake(stakeMap, defaultMode))
+		}
+		return stakes, nil
+	}
+	return nil, fmt.Errorf("invalid stake data")
+}
+
+// convertStake reads one stake entry; the type defaults to cash and the
+// mode to defaultMode
+func convertStake(data map[string]interface{}, defaultMode string) models.Stake {
+	stakeType := getStringValue(data, "type")
+	if stakeType == "" {
+		stakeType = models.StakeTypeCash
+	}
+	mode := getStringValue(data, "mode")
+	if mode == "" {
+		mode = defaultMode
+	}
 	return models.Stake{
-		Type:     "cash",
-		Amount:   getStringValue(data, "amount"),
+		Type:     stakeType,
+		Amount:   getDecimalValue(data, "amount"),
 		Currency: getStringValue(data, "currency"),
+		Mode:     mode,
 	}
 }
 
@@ -492,6 +795,34 @@ func getStringValue(data map[string]interface{}, key string) string {
 	return ""
 }
 
+// getDecimalValue reads a decimal sent either as a string or a JSON number,
+// returning it in exact decimal form ("" if missing or invalid)
+func getDecimalValue(data map[string]interface{}, key string) string {
+	switch val := data[key].(type) {
+	case string:
+		return val
+	case json.Number:
+		if d, err := decimal.Parse(val.String()); err == nil {
+			return d.String()
+		}
+	case float64:
+		return decimal.NewFromFloat(val).String()
+	}
+	return ""
+}
+
+// getIntValue reads an integer sent as a JSON number
+func getIntValue(data map[string]interface{}, key string) (int, bool) {
+	switch val := data[key].(type) {
+	case json.Number:
+		n, err := val.Int64()
+		return int(n), err == nil
+	case float64:
+		return int(val), val == float64(int(val))
+	}
+	return 0, false
+}
+
 func getDefaultContext(cfg *config.Config) *models.Context {
 	return &models.Context{
 		LimitID: 1,
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"sync"
@@ -76,8 +77,11 @@ func (c *Client) readPump() {
 		// Handle different message types
 		switch baseMsg.Type {
 		case MessageTypePlaceBet:
+			// Decode numbers as json.Number so odds and amounts keep every digit
 			var req PlaceBetRequest
-			if err := json.Unmarshal(message, &req); err != nil {
+			decoder := json.NewDecoder(bytes.NewReader(message))
+			decoder.UseNumber()
+			if err := decoder.Decode(&req); err != nil {
 				log.Printf("Failed to parse place_bet request: %v", err)
 				c.SendError("", "Invalid place_bet request", nil)
 				continue
@@ -99,6 +103,18 @@ func (c *Client) readPump() {
 				Request: &req,
 			}
 
+		case MessageTypeAcceptReoffer:
+			var req AcceptReofferRequest
+			if err := json.Unmarshal(message, &req); err != nil {
+				log.Printf("Failed to parse accept_reoffer request: %v", err)
+				c.SendError("", "Invalid accept_reoffer request", nil)
+				continue
+			}
+			c.hub.reofferAcceptances <- &ReofferAcceptance{
+				Client:  c,
+				Request: &req,
+			}
+
 		case MessageTypePing:
 			// Respond with pong
 			c.SendPong()
@@ -17,6 +17,12 @@ type StatusQuery struct {
 	Request *QueryBetStatusRequest
 }
 
+// ReofferAcceptance represents an accept_reoffer request from a client
+type ReofferAcceptance struct {
+	Client  *Client
+	Request *AcceptReofferRequest
+}
+
 // Hub maintains the set of active clients and broadcasts messages to clients
 type Hub struct {
 	// Registered clients
@@ -37,6 +43,9 @@ type Hub struct {
 	// Status queries from clients
 	statusQueries chan *StatusQuery
 
+	// Alternative stake acceptances from clients
+	reofferAcceptances chan *ReofferAcceptance
+
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
 }
@@ -50,6 +59,8 @@ func NewHub() *Hub {
 		unregister:    make(chan *Client),
 		betRequests:   make(chan *BetRequest, 256),
 		statusQueries: make(chan *StatusQuery, 256),
+
+		reofferAcceptances: make(chan *ReofferAcceptance, 256),
 	}
 }
 
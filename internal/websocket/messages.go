@@ -1,6 +1,11 @@
 package websocket
 
-import "time"
+import (
+	"time"
+
+	"github.com/gdsZyy/mts-service/internal/currency"
+	"github.com/gdsZyy/mts-service/internal/models"
+)
 
 // MessageType represents the type of WebSocket message
 type MessageType string
@@ -9,6 +14,7 @@ const (
 	// Client to Server
 	MessageTypePlaceBet       MessageType = "place_bet"
 	MessageTypeQueryBetStatus MessageType = "query_bet_status"
+	MessageTypeAcceptReoffer  MessageType = "accept_reoffer"
 	MessageTypePing           MessageType = "ping"
 
 	// Server to Client
@@ -21,6 +27,8 @@ const (
 	MessageTypeBetResultDelayed      MessageType = "bet_result_delayed"
 	MessageTypeBetStatus             MessageType = "bet_status"
 	MessageTypeBetError              MessageType = "bet_error"
+	MessageTypeBetReoffer            MessageType = "bet_reoffer"
+	MessageTypeBetOddsChanged        MessageType = "bet_odds_changed"
 	MessageTypePong                  MessageType = "pong"
 )
 
@@ -44,6 +52,14 @@ type QueryBetStatusRequest struct {
 	TicketID string `json:"ticketId"`
 }
 
+// AcceptReofferRequest accepts the alternative stake offered in a bet_reoffer message
+type AcceptReofferRequest struct {
+	BaseMessage
+	RequestID  string `json:"requestId"`
+	TicketID   string `json:"ticketId"`             // The rejected ticket
+	OddsFormat string `json:"oddsFormat,omitempty"` // Format of odds in the result
+}
+
 // PingMessage represents a heartbeat ping from client
 type PingMessage struct {
 	BaseMessage
@@ -77,7 +93,10 @@ type BetResultResponse struct {
 	TicketID  string                 `json:"ticketId,omitempty"`
 	Status    string                 `json:"status"` // accepted, rejected
 	Details   map[string]interface{} `json:"details"`
-	Summary   *BetSummary            `json:"summary,omitempty"` // For multi bets
+	Issues    []models.Issue         `json:"issues,omitempty"`   // Explained MTS codes
+	Currency  *currency.TicketRecord `json:"currency,omitempty"` // Stake in the settlement currency and the rates MTS applied
+	Bets      []models.BetMapping    `json:"bets,omitempty"`     // Request index and type of each bet in the response
+	Summary   *BetSummary            `json:"summary,omitempty"`  // For multi bets
 }
 
 // BetPartialResultResponse sent for each completed bet in a multi-bet request
@@ -88,6 +107,9 @@ type BetPartialResultResponse struct {
 	TicketID  string                 `json:"ticketId"`
 	Status    string                 `json:"status"`
 	Details   map[string]interface{} `json:"details"`
+	Issues    []models.Issue         `json:"issues,omitempty"`   // Explained MTS codes
+	Currency  *currency.TicketRecord `json:"currency,omitempty"` // Stake in the settlement currency and the rates MTS applied
+	Bets      []models.BetMapping    `json:"bets,omitempty"`     // Request index and type of each bet in the response
 }
 
 // BetTimeoutResponse sent when MTS doesn't respond within timeout period
@@ -122,6 +144,27 @@ type BetErrorResponse struct {
 	Details   map[string]interface{} `json:"details,omitempty"`
 }
 
+// BetReofferResponse sent after bet_result when MTS offered a lower stake;
+// the client accepts it with accept_reoffer before ExpiresAt
+type BetReofferResponse struct {
+	BaseMessage
+	RequestID string `json:"requestId"`
+	*models.Reoffer
+}
+
+// BetOddsChangedResponse sent when MTS rejects a ticket because odds moved.
+// When the request's odds-change policy allows the new odds, the ticket is
+// resubmitted as NewTicketID and its result follows as usual.
+type BetOddsChangedResponse struct {
+	BaseMessage
+	RequestID   string              `json:"requestId"`
+	TicketID    string              `json:"ticketId"`
+	Policy      string              `json:"policy"` // none, higher or any
+	Changes     []models.OddsChange `json:"changes"`
+	Resubmitted bool                `json:"resubmitted"`
+	NewTicketID string              `json:"newTicketId,omitempty"`
+}
+
 // PongMessage sent in response to ping
 type PongMessage struct {
 	BaseMessage
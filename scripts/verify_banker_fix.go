@@ -35,7 +35,10 @@ func main() {
 	)
 
 	// Build ticket
-	ticket := builder.Build("test-correlation-id")
+	ticket, err := builder.Build("test-correlation-id")
+	if err != nil {
+		log.Fatalf("Failed to build ticket: %v", err)
+	}
 
 	// Marshal to JSON
 	jsonData, err := json.MarshalIndent(ticket, "", "  ")